@@ -0,0 +1,88 @@
+package treefs
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// Annotations appends comments, e.g. "# entry points", after the entries
+// whose slash-separated path in the fs.FS is a key of comments, so that
+// project layout docs can be generated without post-processing the graph.
+//
+// The comments of a graph are aligned to the same column, unless OnLine is
+// applied since its lines are output before the widest one is known.
+func Annotations(comments map[string]string) Opt {
+	return func(t *TreeFS) {
+		if t.comments == nil {
+			t.comments = make(map[string]string, len(comments))
+		}
+		for p, comment := range comments {
+			t.comments[path.Clean(p)] = comment
+		}
+	}
+}
+
+// Decorate appends the string returned by fn after the name, and any
+// annotations, of each entry but the root, enabling arbitrary suffixes such
+// as review status or links without a dedicated Opt for each.
+//
+// fn is called with the slash-separated path of the entry in the fs.FS and
+// its fs.DirEntry. Empty strings are not appended.
+func Decorate(fn func(path string, d fs.DirEntry) string) Opt {
+	return func(t *TreeFS) {
+		t.decorate = fn
+	}
+}
+
+// Return the comment of the entry with path p, with its leading spaces, or
+// an empty string if it has none.
+func (t *TreeFS) comment(p string) string {
+	comment, ok := t.comments[p]
+	if !ok {
+		return ""
+	}
+	return "  # " + comment
+}
+
+// Pad the comments of lines so that they start at the same column, two
+// columns after the widest of the commented lines.
+func alignComments(lines []Line) {
+	widths := make([]int, len(lines))
+	var widest int
+	for i, line := range lines {
+		if line.Comment == "" {
+			continue
+		}
+		line.Comment = ""
+		widths[i] = width(line.String())
+		widest = max(widest, widths[i])
+	}
+
+	for i := range lines {
+		if lines[i].Comment == "" {
+			continue
+		}
+		comment := strings.TrimLeft(lines[i].Comment, " ")
+		lines[i].Comment = strings.Repeat(" ", widest-widths[i]+2) + comment
+	}
+}
+
+// Return the number of columns s occupies in a terminal, ignoring any SGR
+// escape sequences.
+func width(s string) int {
+	var n int
+	for len(s) > 0 {
+		if strings.HasPrefix(s, "\x1b[") {
+			if i := strings.IndexByte(s, 'm'); i >= 0 {
+				s = s[i+1:]
+				continue
+			}
+		}
+		_, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		n++
+	}
+	return n
+}
@@ -0,0 +1,76 @@
+package treefs
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestAnnotations(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"cmd/tool/main.go":    {},
+		"internal/parse/x.go": {},
+		"README.md":           {},
+	}
+
+	tfs, err := New(mapfs, ".", Annotations(map[string]string{
+		".":               "the module",
+		"cmd":             "entry points",
+		"internal/parse/": "the parser",
+		"README.md":       "start here",
+		"does/not/exist":  "ignored",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, tfs.Graph(), `
+.              # the module
+├── README.md  # start here
+├── cmd        # entry points
+│   └── tool
+│       └── main.go
+└── internal
+    └── parse  # the parser
+        └── x.go`[1:])
+}
+
+func TestAnnotationsOnLine(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.test":   {},
+		"a/b/b1.test": {},
+	}
+
+	var lines []string
+	_, err := New(mapfs, "a",
+		Annotations(map[string]string{"a/b/b1.test": "deep"}),
+		OnLine(func(line string) { lines = append(lines, line) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := lines[3], "    └── b1.test  # deep"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestDecorate(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.test":   {Data: []byte("hello")},
+		"a/b/b1.test": {},
+	}
+
+	tfs, err := New(mapfs, "a", Decorate(func(p string, d fs.DirEntry) string {
+		if d.IsDir() {
+			return ""
+		}
+		return "(" + p + ")"
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, tfs.Graph(), `
+a
+├── a1.test (a/a1.test)
+└── b
+    └── b1.test (a/b/b1.test)`[1:])
+}
@@ -0,0 +1,32 @@
+package treefs
+
+import "io/fs"
+
+// Classify appends an indicator of its type to the name of each entry, like
+// `tree -F`: "/" to directories, "*" to executables, "@" to symbolic links,
+// "|" to named pipes and "=" to sockets.
+//
+// Executables are only recognized if the fs.FS reports permission bits.
+func Classify(t *TreeFS) {
+	t.classify = true
+}
+
+// Return the Classify indicator of the Node n, if any.
+func (t *TreeFS) indicator(n *Node) string {
+	mode := n.mode()
+	switch {
+	case n.IsDir:
+		return "/"
+	case mode&fs.ModeSymlink != 0:
+		return "@"
+	case mode&fs.ModeNamedPipe != 0:
+		return "|"
+	case mode&fs.ModeSocket != 0:
+		return "="
+	case mode.IsRegular():
+		if fi, _ := n.info(); fi != nil && fi.Mode()&0o111 != 0 {
+			return "*"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+package treefs
+
+import (
+	"io"
+	"io/fs"
+	"os"
+)
+
+// DefaultDepthPalette is the palette used by ColorConnectorsByDepth and
+// ColorNamesByDepth when no palette is given: red, green, yellow, blue,
+// magenta and cyan.
+var DefaultDepthPalette = []string{"31", "32", "33", "34", "35", "36"}
+
+// ColorConnectorsByDepth colors the connectors of each entry, and the pipes
+// continuing them, by the entry's depth.
+//
+// Each element of palette is a set of ANSI SGR parameters, e.g. "1;34" for
+// bold blue, and the palette repeats once exhausted. If palette is empty,
+// DefaultDepthPalette is used.
+func ColorConnectorsByDepth(palette ...string) Opt {
+	if len(palette) == 0 {
+		palette = DefaultDepthPalette
+	}
+	return func(t *TreeFS) {
+		t.connectorPalette = palette
+	}
+}
+
+// ColorNamesByDepth colors the name of each entry by its depth, in the same
+// way ColorConnectorsByDepth colors connectors.
+func ColorNamesByDepth(palette ...string) Opt {
+	if len(palette) == 0 {
+		palette = DefaultDepthPalette
+	}
+	return func(t *TreeFS) {
+		t.namePalette = palette
+	}
+}
+
+// ColorFunc colors the name of each entry with fn, which is given the path
+// and fs.DirEntry of the entry and returns the ANSI escape sequences to wrap
+// its name in. ColorFunc takes precedence over Color and ColorNamesByDepth.
+func ColorFunc(fn func(path string, d fs.DirEntry) (prefix, suffix string)) Opt {
+	return func(t *TreeFS) {
+		t.colorFunc = fn
+	}
+}
+
+// Report whether colors are disabled by the NO_COLOR environment variable,
+// see https://no-color.org.
+func noColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// Report whether w is a file, such as a pipe, rather than a terminal, in
+// which case colors are disabled. Other io.Writers, e.g. buffers, are
+// assumed to be rendered by the caller.
+func notTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err != nil || fi.Mode()&os.ModeCharDevice == 0
+}
+
+// Paint the connector s of an entry at depth depth.
+func (t *TreeFS) paintConnector(s string, depth int) string {
+	if t.noColor {
+		return s
+	}
+	return paintByDepth(t.connectorPalette, s, depth)
+}
+
+// Paint the name s of the Node n.
+func (t *TreeFS) paintName(s string, n *Node) string {
+	switch {
+	case t.noColor:
+		return s
+	case t.colorFunc != nil:
+		prefix, suffix := t.colorFunc(n.Path, n.entry)
+		return prefix + s + suffix
+	case t.lsColors != nil:
+		if params := t.lsColor(n); params != "" {
+			return sgr(params, s)
+		}
+		return s
+	}
+	return paintByDepth(t.namePalette, s, n.depth)
+}
+
+// Wrap s in the color of palette for depth, cycling through palette, or
+// return s unchanged if palette is empty.
+func paintByDepth(palette []string, s string, depth int) string {
+	if len(palette) == 0 || depth < 1 {
+		return s
+	}
+	return sgr(palette[(depth-1)%len(palette)], s)
+}
+
+// Wrap s in the ANSI SGR sequence with parameters params, resetting all
+// attributes afterwards.
+func sgr(params, s string) string {
+	return "\x1b[" + params + "m" + s + "\x1b[0m"
+}
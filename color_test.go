@@ -0,0 +1,93 @@
+package treefs
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestColorByDepth(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/b/c/c1.test": {},
+		"z.test":        {},
+	}
+
+	// Wrap s in the color code.
+	c := func(code, s string) string {
+		return "\x1b[" + code + "m" + s + "\x1b[0m"
+	}
+
+	got, err := Graph(mapfs, ".", ColorConnectorsByDepth("1", "2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := ".\n" +
+		c("1", "├──") + " a\n" +
+		c("1", "│   ") + c("2", "└──") + " b\n" +
+		c("1", "│   ") + "    " + c("1", "└──") + " c\n" +
+		c("1", "│   ") + "    " + "    " + c("2", "└──") + " c1.test\n" +
+		c("1", "└──") + " z.test"
+	compare(t, got, expected)
+
+	got, err = Graph(mapfs, ".", ColorNamesByDepth("1", "2"), Level(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = ".\n" +
+		"├── " + c("1", "a") + "\n" +
+		"│   └── " + c("2", "b") + "\n" +
+		"└── " + c("1", "z.test")
+	compare(t, got, expected)
+}
+
+func TestColorFunc(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/b/b1.test": {},
+		"a/a1.go":     {},
+	}
+	underline := func(p string, d fs.DirEntry) (string, string) {
+		if strings.HasSuffix(p, ".go") && !d.IsDir() {
+			return "\x1b[4m", "\x1b[24m"
+		}
+		return "", ""
+	}
+
+	got, err := Graph(mapfs, "a", ColorFunc(underline), Color)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, got, "a\n├── \x1b[4ma1.go\x1b[24m\n└── b\n    └── b1.test")
+
+	// NO_COLOR disables every color.
+	t.Setenv("NO_COLOR", "1")
+	got, err = Graph(mapfs, "a", ColorFunc(underline), ColorConnectorsByDepth())
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, got, "a\n├── a1.go\n└── b\n    └── b1.test")
+}
+
+func TestPrinterNotTerminal(t *testing.T) {
+	mapfs := fstest.MapFS{"a/a1.test": {}}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	p := NewPrinter(ColorNamesByDepth())
+	if err := p.Print(mapfs, "a", w); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, string(got), "a\n└── a1.test\n\n0 directories, 1 file\n")
+}
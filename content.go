@@ -0,0 +1,55 @@
+package treefs
+
+import (
+	"io"
+	"regexp"
+)
+
+// defaultContentLimit is the number of bytes ContentMatch reads when given a
+// non-positive limit.
+const defaultContentLimit = 1 << 20
+
+// ContentMatch displays only files whose contents match re, e.g. for a tree
+// of the files containing some identifier during a code audit.
+//
+// At most the first limit bytes of each file are searched, 1 MiB if limit is
+// not positive. Directories left without any displayed entries are omitted.
+func ContentMatch(re *regexp.Regexp, limit int64) Opt {
+	if limit <= 0 {
+		limit = defaultContentLimit
+	}
+	return func(t *TreeFS) {
+		// Ignore if re is nil.
+		if re == nil {
+			return
+		}
+		t.contentRe, t.contentLimit = re, limit
+		t.prune = true
+	}
+}
+
+// Report whether the contents of the file Node n match the ContentMatch
+// pattern of t.
+func (t *TreeFS) contentMatch(n *Node) bool {
+	if n.entry != nil && !n.entry.Type().IsRegular() {
+		return false
+	}
+
+	f, err := t.fsys.Open(n.Path)
+	if err != nil {
+		if t.strict {
+			t.fail("open", n.Path, err)
+		}
+		return false
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, t.contentLimit))
+	if err != nil {
+		if t.strict {
+			t.fail("read", n.Path, err)
+		}
+		return false
+	}
+	return t.contentRe.Match(data)
+}
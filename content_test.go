@@ -0,0 +1,29 @@
+package treefs
+
+import (
+	"regexp"
+	"testing"
+	"testing/fstest"
+)
+
+func TestContentMatch(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.go":   {Data: []byte("package a\n\nfunc Secret() {}\n")},
+		"a/a2.go":   {Data: []byte("package a\n")},
+		"b/b1.go":   {Data: []byte("package b\n")},
+		"c.go":      {Data: []byte("package c // Secret\n")},
+		"d/late.go": {Data: []byte("package d\n\n// nothing to see here\n// Secret\n")},
+	}
+
+	tfs, err := New(mapfs, ".", ContentMatch(regexp.MustCompile(`\bSecret\b`), 30))
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, tfs.String(), `
+.
+├── a
+│   └── a1.go
+└── c.go
+
+1 directory, 2 files`[1:])
+}
@@ -0,0 +1,65 @@
+package treefs
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvHeader is the header row written by WriteCSV and WriteTSV.
+var csvHeader = []string{"path", "type", "depth", "size", "mtime"}
+
+// WriteCSV writes a CSV table of the entries of the TreeFS t to w, with a
+// header row followed by one row per entry, in graph order:
+//
+//	path,type,depth,size,mtime
+//	testdata/a/b,directory,0,,
+//	testdata/a/b/b1.test,file,1,12,2022-06-15T12:30:00Z
+//
+// Sizes and modification times are left empty when unknown, e.g. for roots.
+func (t TreeFS) WriteCSV(w io.Writer) error {
+	return t.writeTable(w, ',')
+}
+
+// WriteTSV is like WriteCSV, but separates fields with tabs.
+func (t TreeFS) WriteTSV(w io.Writer) error {
+	return t.writeTable(w, '\t')
+}
+
+// Write the table of the entries of t to w, with fields separated by comma.
+func (t TreeFS) writeTable(w io.Writer, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	var write func(n *Node) error
+	write = func(n *Node) error {
+		row := []string{n.Path, entryType(n.mode()), strconv.Itoa(n.depth), "", ""}
+		if fi, _ := n.info(); fi != nil {
+			row[3] = strconv.FormatInt(fi.Size(), 10)
+			if mtime := fi.ModTime(); !mtime.IsZero() {
+				row[4] = mtime.Format(time.RFC3339)
+			}
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+		for _, child := range n.Children {
+			if err := write(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, root := range t.roots {
+		if err := write(root); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
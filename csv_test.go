@@ -0,0 +1,49 @@
+package treefs
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestWriteCSV(t *testing.T) {
+	mtime := time.Date(2022, time.June, 15, 12, 30, 0, 0, time.UTC)
+	mapfs := fstest.MapFS{
+		"a/a1.test":   {Data: make([]byte, 12), ModTime: mtime},
+		"a/b,c.test":  {ModTime: mtime},
+		"a/d/d1.test": {Data: make([]byte, 3), ModTime: mtime},
+	}
+	tfs, err := New(mapfs, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := tfs.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := `
+path,type,depth,size,mtime
+a,directory,0,,
+a/a1.test,file,1,12,2022-06-15T12:30:00Z
+"a/b,c.test",file,1,0,2022-06-15T12:30:00Z
+a/d,directory,1,0,
+a/d/d1.test,file,2,3,2022-06-15T12:30:00Z
+`[1:]
+	compare(t, buf.String(), expected)
+
+	buf.Reset()
+	if err := tfs.WriteTSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected = `
+path	type	depth	size	mtime
+a	directory	0		
+a/a1.test	file	1	12	2022-06-15T12:30:00Z
+a/b,c.test	file	1	0	2022-06-15T12:30:00Z
+a/d	directory	1	0	
+a/d/d1.test	file	2	3	2022-06-15T12:30:00Z
+`[1:]
+	compare(t, buf.String(), expected)
+}
@@ -0,0 +1,98 @@
+package treefs
+
+import (
+	"io/fs"
+	"strings"
+)
+
+// DepthRange displays only the entries whose depth is between min and max,
+// inclusive, where the entries of the root are at depth 1, so that reports
+// can focus on, say, the second and third levels of a monorepo.
+//
+// Entries at depth min are displayed as entries of the root, named by their
+// path relative to the root. A max <= 0 sets no max depth, similar to Level.
+// The metadata only counts the displayed entries.
+func DepthRange(min, max int) Opt {
+	return func(t *TreeFS) {
+		if max > 0 {
+			t.level = max
+		}
+		if min > 1 && (max <= 0 || min <= max) {
+			t.minDepth = min
+		}
+	}
+}
+
+// MinLevel displays only the entries at depth n or deeper, like DepthRange
+// with no max depth, so that only the leaves of a deep layout are rendered:
+//
+//	.
+//	├── services/api/handlers
+//	│   └── user.go
+//	└── services/web/pages
+//	    └── index.go
+//
+// It can be combined with Level to set a max depth.
+func MinLevel(n int) Opt {
+	return DepthRange(n, 0)
+}
+
+// Return the entries displayed as entries of the root Node root.
+//
+// Without DepthRange, these are the Children of root. With it, they are all
+// the entries at the min depth, and the entries above them are uncounted.
+func (t *TreeFS) top(root *Node) []*Node {
+	if t.minDepth <= 1 {
+		return root.Children
+	}
+
+	if t.labels == nil {
+		t.labels = make(map[*Node]string)
+	}
+	prefix := root.Path + "/"
+	if root.Path == "." {
+		prefix = ""
+	}
+
+	var entries []*Node
+	var lift func(n *Node)
+	lift = func(n *Node) {
+		for _, child := range n.Children {
+			if child.depth == t.minDepth {
+				t.labels[child] = strings.TrimPrefix(child.Path, prefix)
+				entries = append(entries, child)
+				continue
+			}
+
+			// child is above the min depth, so it's not displayed.
+			switch {
+			case child.IsDir:
+				t.NDirs--
+				lift(child)
+			case child.entry != nil && child.entry.Type()&fs.ModeIrregular != 0:
+				t.NIrregular--
+			case child.entry != nil && child.entry.Type()&fs.ModeSymlink != 0:
+				t.NSymlinks--
+			default:
+				t.NFiles--
+			}
+		}
+	}
+	lift(root)
+	return entries
+}
+
+// Drop the entries of the directories at the max level set by Level from the
+// tree rooted at n, once they've been counted.
+func (t *TreeFS) trim(n *Node) {
+	if t.level == 0 {
+		return
+	}
+	if n.depth == t.level {
+		n.Children = nil
+		return
+	}
+	for _, child := range n.Children {
+		t.trim(child)
+	}
+}
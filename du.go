@@ -0,0 +1,68 @@
+package treefs
+
+import "strings"
+
+// DiskUsage displays the size of each directory as the accumulated size of
+// all the entries below it, and the total size in the metadata, like `tree
+// --du`. Sizes are displayed in bytes unless HumanSize or FixedSize is
+// applied.
+//
+// Only displayed entries are accumulated, so files left out by DirOnly or
+// filters, and the contents of directories below the max level set by Level
+// or of opaque directories, are not included.
+func DiskUsage(t *TreeFS) {
+	t.du = true
+	if t.sizeFmt == nil {
+		t.sizeFmt = rawSize
+	}
+}
+
+// Accumulate the sizes of the entries below the directory Node n, in post
+// order, returning the size of n, including its own.
+func (t *TreeFS) diskUsage(n *Node) int64 {
+	var size int64
+	if fi, _ := n.info(); fi != nil {
+		size = fi.Size()
+	}
+	for _, child := range n.Children {
+		if child.IsDir {
+			size += t.diskUsage(child)
+			continue
+		}
+		if fi, _ := child.info(); fi != nil {
+			size += fi.Size()
+		}
+	}
+	n.du = size
+	return size
+}
+
+// Return the total size of t, as displayed in the metadata by DiskUsage.
+func (t TreeFS) usage() string {
+	return strings.TrimSpace(t.sizeFmt(t.totalSize)) + " used in "
+}
+
+// TotalSize appends the total size of the displayed files to the metadata,
+// both in bytes and human-readable, e.g. "3 directories, 9 files,
+// 1,482,114 bytes (1.4M)". Sizes are summed during the walk, so unlike
+// DiskUsage it doesn't display the size of every directory.
+func TotalSize(t *TreeFS) {
+	t.sumSizes = true
+}
+
+// Return the total size of the files of t, as displayed in the metadata by
+// TotalSize.
+func (t TreeFS) totalBytes() string {
+	unit := " bytes"
+	if t.nbytes == 1 {
+		unit = " byte"
+	}
+	return thousands64(t.nbytes) + unit + " (" + strings.TrimSpace(humanSize(t.nbytes)) + ")"
+}
+
+// Add the size of the file Node n to the total size of t, if known.
+func (t *TreeFS) sumSize(n *Node) {
+	if fi, _ := n.info(); fi != nil && fi.Mode().IsRegular() {
+		t.nbytes += fi.Size()
+	}
+}
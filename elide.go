@@ -0,0 +1,44 @@
+package treefs
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// ElideNames shortens each path component longer than width runes by
+// replacing its middle with an ellipsis, e.g. "verylongfilena…sion.txt",
+// keeping graphs readable on narrow terminals.
+func ElideNames(width int) Opt {
+	return func(t *TreeFS) {
+		// Ignore if width is too small to keep anything but the ellipsis.
+		if width < 2 {
+			return
+		}
+		t.elideWidth = width
+	}
+}
+
+// Elide every component of the slash-separated path p longer than width runes.
+func elidePath(p string, width int) string {
+	if utf8.RuneCountInString(p) <= width {
+		return p
+	}
+
+	components := strings.Split(p, "/")
+	for i, c := range components {
+		components[i] = elide(c, width)
+	}
+	return strings.Join(components, "/")
+}
+
+// Replace the middle of s with an ellipsis if it is longer than width runes,
+// keeping its start and end since they are usually the most telling parts.
+func elide(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	head := width / 2
+	tail := width - 1 - head
+	return string(runes[:head]) + "…" + string(runes[len(runes)-tail:])
+}
@@ -0,0 +1,133 @@
+package treefs
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+)
+
+// EventKind is the kind of a traversal Event.
+type EventKind int
+
+const (
+	EnterDir EventKind = iota // a directory is about to be read
+	File                      // a file was found
+	LeaveDir                  // all of a directory's entries were read
+	Error                     // an entry could not be read, or the walk failed
+)
+
+// String implements the stringer interface for EventKind.
+func (k EventKind) String() string {
+	switch k {
+	case EnterDir:
+		return "EnterDir"
+	case File:
+		return "File"
+	case LeaveDir:
+		return "LeaveDir"
+	case Error:
+		return "Error"
+	}
+	return fmt.Sprintf("EventKind(%d)", int(k))
+}
+
+// Event is emitted for every step of the traversal of an fs.FS.
+//
+// Every walked directory, including the root, is both entered and left,
+// even if its entries aren't read because of Level or FileLimit. Directories
+// that are displayed without being walked, i.e. those set by Opaque,
+// junctions and recursive symbolic links, emit neither event, while those
+// that are walked but then omitted, e.g. by Prune for being left without
+// displayed entries, emit both.
+type Event struct {
+	Kind  EventKind
+	Path  string      // the slash-separated path of the entry
+	Depth int         // the depth of the entry, 0 being the root
+	Entry fs.DirEntry // the entry, nil for the root
+	Err   error       // the failure, for Error events
+}
+
+// OnEvent calls fn for every traversal Event, in order, as the fs.FS is
+// walked, so that consumers can build their own incremental UIs.
+//
+// If fn returns a non-nil error, the walk stops and New returns that error.
+func OnEvent(fn func(Event) error) Opt {
+	return func(t *TreeFS) {
+		t.onEvent = fn
+	}
+}
+
+// OnLine calls fn for every line of the graph, in order, instead of keeping
+// the lines in the TreeFS, enabling direct integration with loggers and
+// line-oriented protocols without an intermediate buffer.
+//
+// Since no lines are kept, the Graph of the resulting TreeFS is empty and its
+// String only contains the metadata.
+func OnLine(fn func(line string)) Opt {
+	return func(t *TreeFS) {
+		t.onLine = fn
+	}
+}
+
+// Events walks the fs.FS fsys with name name in a new goroutine and sends
+// every traversal Event on the returned channel, which is closed once the
+// walk is complete.
+//
+// If New fails, e.g. because name doesn't exist, Strict found unreadable
+// entries or a limit was exceeded, a final Error event for name with its
+// error is sent before the channel is closed.
+//
+// Cancelling ctx stops the walk and closes the channel, so consumers that
+// stop receiving early must cancel ctx to release the goroutine.
+func Events(ctx context.Context, fsys fs.FS, name string, opts ...Opt) <-chan Event {
+	ch := make(chan Event)
+	send := OnEvent(func(ev Event) error {
+		select {
+		case ch <- ev:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	go func() {
+		defer close(ch)
+		_, err := New(fsys, name, append(opts[:len(opts):len(opts)], send)...)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		select {
+		case ch <- Event{Kind: Error, Path: name, Err: err}:
+		case <-ctx.Done():
+		}
+	}()
+	return ch
+}
+
+// Emit an Event of kind kind for the Node n to t's OnEvent callback, or
+// stop the walk if t's context is done.
+func (t *TreeFS) event(kind EventKind, n *Node, err error) error {
+	if t.ctx != nil {
+		if cerr := t.ctx.Err(); cerr != nil {
+			return cerr
+		}
+	}
+	if t.onEvent == nil && t.jsonLines == nil {
+		return nil
+	}
+
+	ev := Event{
+		Kind:  kind,
+		Path:  n.Path,
+		Depth: n.depth,
+		Entry: n.entry,
+		Err:   err,
+	}
+	if jerr := t.writeJSONLine(ev, n); jerr != nil {
+		return jerr
+	}
+	if t.onEvent == nil {
+		return nil
+	}
+	return t.onEvent(ev)
+}
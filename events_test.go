@@ -0,0 +1,153 @@
+package treefs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEvents(t *testing.T) {
+	fsys := errFS{
+		MapFS: fstest.MapFS{
+			"a1.test":   {},
+			"b/b1.test": {},
+			"c/c1.test": {},
+		},
+		dirs: map[string]bool{"c": true},
+	}
+
+	var got []string
+	_, err := New(fsys, ".", Strict, OnEvent(func(ev Event) error {
+		got = append(got, fmt.Sprintf("%s %s %d", ev.Kind, ev.Path, ev.Depth))
+		return nil
+	}))
+	if !errors.Is(err, errUnreadable) {
+		t.Fatalf("expected %v, got %v", errUnreadable, err)
+	}
+
+	expected := []string{
+		"EnterDir . 0",
+		"File a1.test 1",
+		"EnterDir b 1",
+		"File b/b1.test 2",
+		"LeaveDir b 1",
+		"EnterDir c 1",
+		"Error c 1",
+		"LeaveDir c 1",
+		"LeaveDir . 0",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestEventsNotWalked(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a.go":         {},
+		"b/up":         {Data: []byte(".."), Mode: fs.ModeSymlink},
+		"b/b1.go":      {},
+		"o/o1.go":      {},
+		"p/p1.test":    {},
+		"q/r/r1.go":    {},
+		"q/r/s/s1.go":  {},
+		"q/r/s/t/t.go": {},
+	}
+
+	var got []string
+	tfs, err := New(mapfs, ".", Match("*.go"), Prune, Opaque("o"), FollowSymlinks, Level(3), OnEvent(func(ev Event) error {
+		if ev.Kind != File {
+			got = append(got, fmt.Sprintf("%s %s", ev.Kind, ev.Path))
+		}
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The opaque o and the recursive b/up are displayed but not walked, the
+	// pruned p is walked but not displayed and q/r/s is walked, but not
+	// read, at the max level.
+	expected := []string{
+		"EnterDir .",
+		"EnterDir b",
+		"LeaveDir b",
+		"EnterDir p",
+		"LeaveDir p",
+		"EnterDir q",
+		"EnterDir q/r",
+		"EnterDir q/r/s",
+		"LeaveDir q/r/s",
+		"LeaveDir q/r",
+		"LeaveDir q",
+		"LeaveDir .",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+	for _, s := range []string{"├── o [1 entry]", "up -> .. [recursive, not followed]"} {
+		if !strings.Contains(tfs.Graph(), s) {
+			t.Errorf("expected the graph to contain %q, got:\n%s", s, tfs.Graph())
+		}
+	}
+	if strings.Contains(tfs.Graph(), "p1.test") || strings.Contains(tfs.Graph(), "── p\n") {
+		t.Errorf("expected p to be pruned, got:\n%s", tfs.Graph())
+	}
+}
+
+func TestEventsChannel(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a1.test":   {},
+		"b/b1.test": {},
+	}
+
+	var n int
+	for range Events(context.Background(), mapfs, ".") {
+		n++
+	}
+	if n != 6 {
+		t.Fatalf("expected 6 events, got %d", n)
+	}
+
+	// Receiving a single event and cancelling must not leak the walk.
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := Events(ctx, mapfs, ".")
+	<-ch
+	cancel()
+	for range ch {
+	}
+
+	// The error of a failed walk is sent last.
+	var got []Event
+	for ev := range Events(context.Background(), mapfs, "missing") {
+		got = append(got, ev)
+	}
+	if len(got) != 1 || got[0].Kind != Error || got[0].Path != "missing" || !errors.Is(got[0].Err, ErrNotExist) {
+		t.Fatalf("expected a single Error event for missing, got %v", got)
+	}
+}
+
+func TestOnLine(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a1.test":   {},
+		"b/b1.test": {},
+	}
+
+	var lines []string
+	tfs, err := New(mapfs, ".", OnLine(func(line string) {
+		lines = append(lines, line)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{".", "├── a1.test", "└── b", "    └── b1.test"}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Fatalf("expected %q, got %q", expected, lines)
+	}
+	compare(t, tfs.String(), "1 directory, 2 files")
+}
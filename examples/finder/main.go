@@ -0,0 +1,95 @@
+/*
+MIT License
+
+Copyright (c) 2022-present Milad Michael Nasrollahi
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/Algebra8/treefs"
+)
+
+var hidden bool
+
+func init() {
+	flag.BoolVar(&hidden, "a", false, `
+Include directory entries whose names begin with a dot ('.') except for . and 
+...`[1:])
+}
+
+// Run stty with the arguments args on the terminal, returning its output.
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+func main() {
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "%s [-a] [directory]\n", os.Args[0])
+		os.Exit(2)
+	}
+	dir := args[0]
+
+	var opts []treefs.Opt
+	if hidden {
+		// Allow hidden directories and entries to be shown.
+		opts = append(opts, treefs.Hidden)
+	}
+
+	// Keys are read as they are typed, so the terminal is put in raw mode,
+	// and restored once an entry is selected.
+	state, err := stty("-g")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "stdin is not a terminal:", err)
+		os.Exit(1)
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	// Like fzf, the finder is drawn on stderr so that the selected path can
+	// be piped from stdout.
+	p, err := treefs.Finder(os.Stdin, os.Stderr, os.DirFS(dir), ".", opts...)
+	stty(state)
+	if errors.Is(err, treefs.ErrNoSelection) {
+		os.Exit(130)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+	fmt.Println(filepath.Join(dir, filepath.FromSlash(p)))
+}
@@ -28,15 +28,24 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/Algebra8/treefs"
 )
 
+// Exit codes, so that the command can be used as a CI check.
+const (
+	exitOK      = 0 // every directory was graphed
+	exitPartial = 1 // one or more directories could not be graphed
+	exitUsage   = 2 // invalid usage
+)
+
 var (
 	hidden        bool
 	dirOnly       bool
 	fullFilePath  bool
 	maxDepthLevel int
+	quiet         bool
 )
 
 func init() {
@@ -46,6 +55,7 @@ Include directory entries whose names begin with a dot ('.') except for . and
 	flag.BoolVar(&dirOnly, "d", false, "List directoris only")
 	flag.BoolVar(&fullFilePath, "f", false, "Prints the full path prefix for each file")
 	flag.IntVar(&maxDepthLevel, "L", -1, "Max display depth of the directory tree")
+	flag.BoolVar(&quiet, "quiet", false, "Only report counts and errors, not the graph")
 }
 
 func main() {
@@ -53,8 +63,8 @@ func main() {
 
 	args := flag.Args()
 	if len(args) < 1 {
-		fmt.Fprintf(os.Stderr, "%s [-adfL] [directory ...]\n", args[0])
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "%s [-adfL] [--quiet] [directory ...]\n", os.Args[0])
+		os.Exit(exitUsage)
 	}
 
 	var opts []treefs.Opt
@@ -73,18 +83,32 @@ func main() {
 
 	var tfsArgs []treefs.Arg
 	for _, dir := range args {
+		// Names are relative to their fs.FS, so each directory is graphed
+		// as the entry of its parent, keeping its name in the graph.
+		parent, base := filepath.Dir(dir), filepath.Base(dir)
+		if base == string(filepath.Separator) {
+			parent, base = dir, "."
+		}
 		tfsArgs = append(tfsArgs, treefs.Arg{
-			Fsys: os.DirFS(dir),
-			Name: dir,
+			Fsys: os.DirFS(parent),
+			Name: base,
 			Opts: opts,
 		})
 	}
 
-	tfs, err := treefs.NewMulti(tfsArgs...)
+	// Graph whichever directories can be, reporting the others.
+	tfs, _, err := treefs.NewMultiPartial(tfsArgs...)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
-		os.Exit(1)
 	}
 
-	fmt.Println(tfs)
+	if quiet {
+		fmt.Println(tfs.Meta())
+	} else if tfs.Len() > 0 {
+		fmt.Println(tfs)
+	}
+
+	if err != nil {
+		os.Exit(exitPartial)
+	}
 }
@@ -0,0 +1,292 @@
+package treefs
+
+import (
+	"io/fs"
+	"math"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// MinSize displays only files of at least n bytes.
+//
+// Directories left without any displayed entries are omitted, which makes it
+// useful for finding large blobs accidentally embedded in an fs.FS.
+func MinSize(n int64) Opt {
+	return func(t *TreeFS) {
+		t.minSize = n
+		t.prune = true
+	}
+}
+
+// MaxSize displays only files of at most n bytes.
+//
+// Directories left without any displayed entries are omitted.
+func MaxSize(n int64) Opt {
+	return func(t *TreeFS) {
+		t.maxSize = n
+		t.prune = true
+	}
+}
+
+// NewerThan displays only files modified after tm, turning the graph into a
+// quick "what changed since the last build" view.
+//
+// Directories left without any displayed entries are omitted.
+func NewerThan(tm time.Time) Opt {
+	return func(t *TreeFS) {
+		t.newerThan = tm
+		t.prune = true
+	}
+}
+
+// OlderThan displays only files modified before tm, e.g. to find stale
+// files.
+//
+// Directories left without any displayed entries are omitted.
+func OlderThan(tm time.Time) Opt {
+	return func(t *TreeFS) {
+		t.olderThan = tm
+		t.prune = true
+	}
+}
+
+// Fuzzy displays only entries whose path, relative to the root, fuzzily
+// matches pattern, i.e. contains the characters of pattern in order, like
+// fzf. Matching is case insensitive unless pattern contains an upper case
+// character.
+//
+// Directories are displayed if they match, or if any of their entries are
+// displayed, so the ancestors of every match are kept. See Finder to filter
+// interactively.
+func Fuzzy(pattern string) Opt {
+	return func(t *TreeFS) {
+		// Ignore if pattern is empty.
+		if pattern == "" {
+			return
+		}
+		t.fuzzy = pattern
+		t.prune = true
+	}
+}
+
+// Match displays only files whose name matches one of the shell patterns
+// patterns, like tree -P. Patterns use the syntax of path.Match and may hold
+// several alternatives separated by "|", e.g. "*.go|go.mod".
+//
+// Directories are still walked and displayed, even if left without any
+// displayed entries, unless Prune is applied.
+func Match(patterns ...string) Opt {
+	return func(t *TreeFS) {
+		for _, pattern := range patterns {
+			// Ignore if pattern is empty.
+			if pattern != "" {
+				t.match = append(t.match, pattern)
+			}
+		}
+	}
+}
+
+// Prune omits directories left without any displayed entries, like
+// tree --prune, e.g. to only display the directories holding the files kept
+// by Match.
+func Prune(t *TreeFS) {
+	t.prune = true
+}
+
+// MatchDirs also applies the patterns of Match to the names of directories,
+// like tree --matchdirs. Every entry of a matching directory is displayed,
+// and matching directories are never omitted.
+func MatchDirs(t *TreeFS) {
+	t.matchDirs = true
+}
+
+// Ignore hides the files and directories, along with their entries, whose
+// name matches one of the shell patterns patterns, like
+// tree -I 'node_modules|*.log'. Patterns have the same syntax as for Match.
+//
+// Ignored directories are not walked.
+func Ignore(patterns ...string) Opt {
+	return func(t *TreeFS) {
+		for _, pattern := range patterns {
+			// Ignore if pattern is empty.
+			if pattern != "" {
+				t.ignore = append(t.ignore, pattern)
+			}
+		}
+	}
+}
+
+// IgnoreCase makes the patterns of Match and Ignore case insensitive, like
+// tree --ignore-case.
+func IgnoreCase(t *TreeFS) {
+	t.ignoreCase = true
+}
+
+// Filter displays only the entries for which keep returns true, as a
+// catch-all for policies no other Opt covers. keep is called during the walk
+// with the slash-separated path of each entry in the fs.FS and its
+// fs.DirEntry; directories it rejects are not walked.
+//
+// Since entries are filtered before the graph is rendered, connectors are
+// drawn as if rejected entries never existed.
+func Filter(keep func(path string, d fs.DirEntry) bool) Opt {
+	return WithIgnoreMatcher(IgnoreMatcherFunc(func(path string, d fs.DirEntry) bool {
+		return !keep(path, d)
+	}))
+}
+
+// Extensions displays only files with one of the extensions exts, e.g.
+// Extensions(".go", ".mod"), without the need for patterns. The leading dot
+// may be omitted.
+//
+// Directories left without any displayed entries are omitted.
+func Extensions(exts ...string) Opt {
+	return func(t *TreeFS) {
+		t.exts = addExts(t.exts, exts)
+		if len(t.exts) > 0 {
+			t.prune = true
+		}
+	}
+}
+
+// ExcludeExtensions hides files with any of the extensions exts, in the
+// format of Extensions.
+func ExcludeExtensions(exts ...string) Opt {
+	return func(t *TreeFS) {
+		t.excludedExts = addExts(t.excludedExts, exts)
+	}
+}
+
+// Add the extensions exts to the set set, with their leading dot.
+func addExts(set map[string]bool, exts []string) map[string]bool {
+	for _, ext := range exts {
+		// Ignore if ext is empty.
+		if ext == "" || ext == "." {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if set == nil {
+			set = make(map[string]bool)
+		}
+		set[ext] = true
+	}
+	return set
+}
+
+// Report whether name matches any alternative of any of the patterns,
+// regardless of case if fold is set. Malformed patterns match nothing.
+func matchAny(patterns []string, name string, fold bool) bool {
+	if fold {
+		name = strings.ToLower(name)
+	}
+	for _, pattern := range patterns {
+		if fold {
+			pattern = strings.ToLower(pattern)
+		}
+		for _, alt := range strings.Split(pattern, "|") {
+			if ok, _ := path.Match(alt, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Report whether the path p fuzzily matches the pattern pattern.
+func fuzzyMatch(pattern, p string) bool {
+	if strings.IndexFunc(pattern, unicode.IsUpper) < 0 {
+		p = strings.ToLower(p)
+	}
+	for _, r := range pattern {
+		i := strings.IndexRune(p, r)
+		if i < 0 {
+			return false
+		}
+		p = p[i+len(string(r)):]
+	}
+	return true
+}
+
+// Record whether the Node n is, or is within, a directory matching the
+// patterns of Match if MatchDirs was applied. parent is the directory of n.
+func (t *TreeFS) matchDir(parent, n *Node) {
+	if !t.matchDirs || len(t.match) == 0 {
+		return
+	}
+	n.matched = parent.matched || n.IsDir && matchAny(t.match, n.Name, t.ignoreCase)
+}
+
+// Report whether the file Node n passes the file filters of t.
+func (t *TreeFS) allowFile(n *Node) bool {
+	if t.minSize > 0 || t.maxSize < math.MaxInt64 {
+		fi, err := n.info()
+		if err != nil {
+			return false
+		}
+		if size := fi.Size(); size < t.minSize || size > t.maxSize {
+			return false
+		}
+	}
+
+	if !t.newerThan.IsZero() || !t.olderThan.IsZero() {
+		fi, err := n.info()
+		if err != nil {
+			return false
+		}
+		mtime := fi.ModTime()
+		if !t.newerThan.IsZero() && !mtime.After(t.newerThan) {
+			return false
+		}
+		if !t.olderThan.IsZero() && !mtime.Before(t.olderThan) {
+			return false
+		}
+	}
+
+	if t.fuzzy != "" && !fuzzyMatch(t.fuzzy, n.relPath()) {
+		return false
+	}
+
+	if len(t.exts) > 0 && !t.exts[path.Ext(n.Name)] {
+		return false
+	}
+	if t.excludedExts[path.Ext(n.Name)] {
+		return false
+	}
+
+	if len(t.match) > 0 && !n.matched && !matchAny(t.match, n.Name, t.ignoreCase) {
+		return false
+	}
+
+	// Contents are read last, since it's the most expensive filter.
+	if t.contentRe != nil && !t.contentMatch(n) {
+		return false
+	}
+
+	return true
+}
+
+// Report whether the directory Node n should be omitted because file filters
+// left it without any entries.
+//
+// Directories at the max level set by Level are never pruned since their
+// entries were not read, nor are those exceeding FileLimit, or matching
+// Fuzzy, or Match with MatchDirs.
+func (t *TreeFS) pruned(n *Node) bool {
+	if !t.prune || len(n.Children) > 0 {
+		return false
+	}
+	if t.fuzzy != "" && fuzzyMatch(t.fuzzy, n.relPath()) {
+		return false
+	}
+	if n.matched {
+		return false
+	}
+	if _, ok := t.overLimit[n]; ok {
+		return false
+	}
+	return t.level == 0 || n.depth < t.level
+}
@@ -0,0 +1,160 @@
+package treefs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+// ErrNoSelection is returned by Finder when it is canceled, or its input
+// ends, before an entry is selected.
+var ErrNoSelection = errors.New("treefs: no entry selected")
+
+// Finder runs an interactive, fzf-like finder over the fs.FS fsys with name
+// name, reading keys from in and drawing to out, and returns the path of the
+// selected entry.
+//
+// The fs.FS is walked once, with opts applied. Typing narrows the displayed
+// graph to the entries whose path, relative to name, fuzzily matches the
+// query, like Fuzzy, keeping their ancestors. The up and down arrows, or
+// Ctrl-P and Ctrl-N, move the selection between the matches and Enter writes
+// the path of the selected one to out, followed by a newline. Ctrl-C, Ctrl-D or the end of
+// in cancel the finder, which then returns ErrNoSelection.
+//
+// out is redrawn with ANSI escape sequences after every key, and in must
+// deliver keys as they are typed, so a terminal has to be put in raw mode by
+// the caller, e.g. with `stty raw -echo`.
+func Finder(in io.Reader, out io.Writer, fsys fs.FS, name string, opts ...Opt) (string, error) {
+	tfs, err := New(fsys, name, opts...)
+	if err != nil {
+		return "", err
+	}
+	if len(tfs.roots) == 0 {
+		return "", ErrNoSelection
+	}
+	root := tfs.roots[0]
+	// The graph is redrawn for every key, so it must be kept.
+	tfs.onLine, tfs.stream = nil, nil
+
+	r := bufio.NewReader(in)
+	var query []rune
+	selected := 0
+	for {
+		matches, err := tfs.drawFinder(out, root, string(query), selected)
+		if err != nil {
+			return "", err
+		}
+
+		key, _, err := r.ReadRune()
+		if err == io.EOF {
+			return "", ErrNoSelection
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch key {
+		case '\r', '\n':
+			if len(matches) == 0 {
+				continue
+			}
+			p := matches[selected]
+			if _, err := fmt.Fprint(out, "\x1b[H\x1b[2J"+p+"\r\n"); err != nil {
+				return "", err
+			}
+			return p, nil
+		case 0x03, 0x04: // Ctrl-C, Ctrl-D
+			return "", ErrNoSelection
+		case 0x7f, 0x08: // Backspace
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				selected = 0
+			}
+		case 0x10: // Ctrl-P
+			selected--
+		case 0x0e: // Ctrl-N
+			selected++
+		case 0x1b: // The escape sequences of the arrows, e.g. "\x1b[A".
+			if b, _ := r.Peek(2); len(b) == 2 && b[0] == '[' {
+				r.Discard(2)
+				switch b[1] {
+				case 'A':
+					selected--
+				case 'B':
+					selected++
+				}
+			}
+		default:
+			if key >= ' ' {
+				query = append(query, key)
+				selected = 0
+			}
+		}
+		selected = max(0, min(selected, len(matches)-1))
+	}
+}
+
+// Draw the prompt with the query query, and the graph of the entries below
+// root matching it, to out, highlighting the selected-th match. It returns
+// the paths of the matches, in display order.
+func (t *TreeFS) drawFinder(out io.Writer, root *Node, query string, selected int) ([]string, error) {
+	t.tree, t.nodes, t.truncated = nil, 0, false
+	t.outputBytes, t.outputLines = 0, 0
+	t.emit(Line{Name: root.Name})
+
+	// Whether each Node matches, or has entries matching, query.
+	keep := make(map[*Node]bool)
+	var mark func(n *Node) bool
+	mark = func(n *Node) bool {
+		for _, child := range n.Children {
+			if mark(child) {
+				keep[n] = true
+			}
+		}
+		if query == "" || fuzzyMatch(query, n.relPath()) {
+			keep[n] = true
+		}
+		return keep[n]
+	}
+	mark(root)
+
+	var matches []string
+	highlight := -1
+	var draw func(n *Node, prefix string)
+	draw = func(n *Node, prefix string) {
+		var entries []*Node
+		for _, child := range n.Children {
+			if keep[child] {
+				entries = append(entries, child)
+			}
+		}
+		for i, child := range entries {
+			connector, childPrefix := t.style.Tee, prefix+t.style.Pipe
+			if i == len(entries)-1 {
+				connector, childPrefix = t.style.Elbow, prefix+t.style.Space
+			}
+			t.append(prefix, connector, child, "")
+			if query == "" || fuzzyMatch(query, child.relPath()) {
+				if len(matches) == selected {
+					highlight = len(t.tree) - 1
+				}
+				matches = append(matches, child.Path)
+			}
+			draw(child, childPrefix)
+		}
+	}
+	draw(root, "")
+
+	bw := bufio.NewWriter(out)
+	bw.WriteString("\x1b[H\x1b[2J> " + query + "\r\n")
+	for i, line := range t.tree {
+		if i == highlight {
+			bw.WriteString("\x1b[7m" + line.String() + "\x1b[0m\r\n")
+			continue
+		}
+		bw.WriteString(line.String() + "\r\n")
+	}
+	return matches, bw.Flush()
+}
@@ -0,0 +1,61 @@
+package treefs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFinder(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.test":     {},
+		"a/b/b1.test":   {},
+		"a/b/c/c1.test": {},
+		"a/d/d1.test":   {},
+	}
+
+	tests := []struct {
+		name     string
+		keys     string
+		expected string
+		err      error
+	}{
+		{name: "first entry", keys: "\r", expected: "a/a1.test"},
+		{name: "fuzzy query", keys: "bc1\r", expected: "a/b/c/c1.test"},
+		{name: "backspace", keys: "d1x\x7f\r", expected: "a/d/d1.test"},
+		{name: "arrows", keys: "\x1b[B\x1b[B\x1b[A\r", expected: "a/b"},
+		{name: "ctrl-n and ctrl-p", keys: "b\x0e\x0e\x10\r", expected: "a/b/b1.test"},
+		{name: "selection stays within matches", keys: "\x10\x10\r", expected: "a/a1.test"},
+		{name: "no match", keys: "zz\r", err: ErrNoSelection},
+		{name: "root name not matched", keys: "aa\r", err: ErrNoSelection},
+		{name: "ctrl-c", keys: "b\x03", err: ErrNoSelection},
+	}
+	for _, tc := range tests {
+		var out strings.Builder
+		got, err := Finder(strings.NewReader(tc.keys), &out, mapfs, "a")
+		if !errors.Is(err, tc.err) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.err, err)
+		}
+		if got != tc.expected {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.expected, got)
+		}
+		if tc.err == nil && !strings.HasSuffix(out.String(), "\x1b[H\x1b[2J"+tc.expected+"\r\n") {
+			t.Errorf("%s: expected the selected path to be written, got %q", tc.name, out.String())
+		}
+	}
+
+	// Each frame draws the query and the matches, keeping their ancestors,
+	// with the selected match highlighted.
+	var out strings.Builder
+	if _, err := Finder(strings.NewReader("c1"), &out, mapfs, "a"); !errors.Is(err, ErrNoSelection) {
+		t.Fatalf("expected %v, got %v", ErrNoSelection, err)
+	}
+	frames := strings.Split(out.String(), "\x1b[H\x1b[2J")
+	expected := "> c1\r\n" +
+		"a\r\n" +
+		"└── b\r\n" +
+		"    └── c\r\n" +
+		"\x1b[7m        └── c1.test\x1b[0m\r\n"
+	compare(t, frames[len(frames)-1], expected)
+}
@@ -0,0 +1,99 @@
+package treefs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// FoldIdentical renders sibling directories that are structurally identical
+// to an earlier sibling, i.e. that contain entries with the same names and
+// types at every level, as a single summary line:
+//
+//	├── en
+//	│   └── messages.json
+//	├── fr [same structure as en]
+//
+// This drastically shortens repetitive trees, such as generated locale
+// directories. Folded directories are still counted in the metadata.
+func FoldIdentical(t *TreeFS) {
+	t.foldIdentical = true
+}
+
+// DedupeIdentical renders only the first of the sibling directories that are
+// structurally identical, like with FoldIdentical, followed by a single line
+// with the number of the others:
+//
+//	├── de
+//	│   └── messages.json
+//	├── … 57 identical siblings
+//	└── README.md
+//
+// which keeps huge generated trees readable when the names of the identical
+// siblings don't matter. They are still counted in the metadata.
+func DedupeIdentical(t *TreeFS) {
+	t.dedupeIdentical = true
+}
+
+// Return the entries without the directories structurally identical to an
+// earlier one, along with the number of those left out for each of the
+// earlier ones.
+func dedupe(entries []*Node) ([]*Node, map[*Node]int) {
+	firsts := make(map[string]*Node)
+	dupes := make(map[*Node]int)
+
+	kept := make([]*Node, 0, len(entries))
+	for _, n := range entries {
+		if !n.IsDir || len(n.Children) == 0 {
+			kept = append(kept, n)
+			continue
+		}
+		if first, ok := firsts[n.signature()]; ok {
+			dupes[first]++
+			continue
+		}
+		firsts[n.signature()] = n
+		kept = append(kept, n)
+	}
+	return kept, dupes
+}
+
+// Render the line of the n siblings of a directory left out by
+// DedupeIdentical, preceded by prefix.
+func (t *TreeFS) identical(prefix string, depth, n int, last bool) {
+	connector := t.style.Tee
+	if last {
+		connector = t.style.Elbow
+	}
+	noun := "siblings"
+	if n == 1 {
+		noun = "sibling"
+	}
+	t.emit(Line{
+		Prefix:    prefix,
+		Connector: t.paintConnector(connector, depth),
+		Name:      fmt.Sprintf("… %s identical %s", thousands(n), noun),
+	})
+}
+
+// Return a digest of the structure of the subtree rooted at n: the names and
+// types of all of its descendants, but not its own name.
+func (n *Node) signature() string {
+	if n.sig != "" {
+		return n.sig
+	}
+
+	h := sha256.New()
+	for _, child := range n.Children {
+		h.Write([]byte(child.Name))
+		if child.IsDir {
+			h.Write([]byte{0, 'd'})
+			h.Write([]byte(child.signature()))
+		} else {
+			h.Write([]byte{0, 'f'})
+		}
+		h.Write([]byte{0})
+	}
+	n.sig = hex.EncodeToString(h.Sum(nil))
+	return n.sig
+}
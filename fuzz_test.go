@@ -0,0 +1,178 @@
+package treefs
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// Names used to generate layouts, including odd ones: spaces, non-ASCII,
+// leading dashes, hidden and numeric names.
+var fuzzNames = []string{
+	"a", "B", "c.test", "a b", "é", "-x", "_", ".h", "10", "9", "z~", "日本",
+}
+
+// Generate an fstest.MapFS from the fuzz input data.
+//
+// Each byte is an instruction: its low two bits select whether to create a
+// directory and enter it, create a file, leave the current directory or
+// create an empty directory, and its remaining bits select the name.
+func fuzzLayout(data []byte) fstest.MapFS {
+	mapfs := fstest.MapFS{}
+	var stack []string
+	used := map[string]bool{}
+
+	for i, b := range data {
+		dir := path.Join(append([]string{"."}, stack...)...)
+		name := fuzzNames[int(b>>2)%len(fuzzNames)]
+		// Ensure the entry does not collide with an existing one.
+		p := path.Join(dir, name)
+		if used[p] {
+			name = fmt.Sprintf("%s%d", name, i)
+			p = path.Join(dir, name)
+		}
+
+		switch b & 3 {
+		case 0:
+			if len(stack) == 16 {
+				continue
+			}
+			used[p] = true
+			mapfs[p] = &fstest.MapFile{Mode: fs.ModeDir}
+			stack = append(stack, name)
+		case 1:
+			used[p] = true
+			mapfs[p] = &fstest.MapFile{}
+		case 2:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case 3:
+			used[p] = true
+			mapfs[p] = &fstest.MapFile{Mode: fs.ModeDir}
+		}
+	}
+	return mapfs
+}
+
+func FuzzTree(f *testing.F) {
+	f.Add([]byte{0, 5, 9, 2, 13, 3, 0, 0, 17})
+	f.Add([]byte{1, 5, 9, 13, 17, 21, 25, 29, 33})
+	f.Add([]byte{0, 4, 8, 12, 16, 21, 2, 2, 2, 25, 3, 7})
+	f.Add([]byte{28, 1, 0, 33, 37, 2, 41, 45})
+
+	treeBin, _ := exec.LookPath("tree")
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		mapfs := fuzzLayout(data)
+
+		for _, opts := range [][]Opt{nil, {Hidden}, {DirOnly}, {Level(2)}} {
+			tfs, err := New(mapfs, ".", opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkInvariants(t, tfs)
+		}
+
+		if treeBin != "" {
+			compareWithTree(t, treeBin, mapfs)
+		}
+	})
+}
+
+// Check the structural invariants of the graph of tfs: that every line has a
+// well-formed prefix, that "├──" is always followed by a sibling and "└──"
+// never is, and that the number of lines matches the metadata.
+func checkInvariants(t *testing.T, tfs TreeFS) {
+	t.Helper()
+
+	lines := strings.Split(tfs.Graph(), "\n")
+	depths := make([]int, len(lines))
+	for i, line := range lines[1:] {
+		depth := 1
+		for {
+			if strings.HasPrefix(line, pipePrefix) {
+				line = line[len(pipePrefix):]
+			} else if strings.HasPrefix(line, spacePrefix) {
+				line = line[len(spacePrefix):]
+			} else {
+				break
+			}
+			depth++
+		}
+		if !strings.HasPrefix(line, teeConnector+" ") && !strings.HasPrefix(line, elbowConnector+" ") {
+			t.Fatalf("malformed line %q in graph:\n%s", lines[i+1], tfs.Graph())
+		}
+		depths[i+1] = depth
+	}
+
+	for i := 1; i < len(lines); i++ {
+		// Find whether the entry on line i has a later sibling.
+		sibling := false
+		for j := i + 1; j < len(lines) && depths[j] >= depths[i]; j++ {
+			if depths[j] == depths[i] {
+				sibling = true
+				break
+			}
+		}
+
+		tee := strings.Contains(lines[i], teeConnector)
+		if tee != sibling {
+			t.Fatalf("wrong connector on line %q in graph:\n%s", lines[i], tfs.Graph())
+		}
+	}
+
+	expected := 1 + tfs.NDirs
+	if !tfs.dirOnly {
+		expected += tfs.NFiles
+	}
+	if len(lines) != expected {
+		t.Fatalf("expected %d lines for %q, got %d", expected, tfs.Meta(), len(lines))
+	}
+}
+
+// Compare the output of the tree binary with that of treefs for the layout
+// mapfs, written to a temporary directory.
+func compareWithTree(t *testing.T, treeBin string, mapfs fstest.MapFS) {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, file := range mapfs {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if file.Mode.IsDir() {
+			if err := os.MkdirAll(p, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, file.Data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// With the C locale, tree sorts names by byte like fs.ReadDir, and -N
+	// prints non-ASCII names as is.
+	cmd := exec.Command(treeBin, "-N", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.TrimSuffix(string(out), "\n")
+	got, err := Tree(os.DirFS(dir), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, got, expected)
+}
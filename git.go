@@ -0,0 +1,30 @@
+package treefs
+
+// GitStatusFunc reports the git status of the entry with path path as the two
+// letter XY code of `git status --porcelain`, e.g. " M", "??" or "!!", or an
+// empty string if the entry is unmodified.
+//
+// The gitstatus subpackage provides a GitStatusFunc for a git working tree.
+type GitStatusFunc func(path string) string
+
+// WithGitStatus displays the git status of each entry, as reported by status,
+// similar to modern tree replacements:
+//
+//	.
+//	├── [ M]  main.go
+//	├── [??]  notes.txt
+//	└── [  ]  go.mod
+func WithGitStatus(status GitStatusFunc) Opt {
+	return func(t *TreeFS) {
+		t.gitStatus = status
+	}
+}
+
+// Format the git status of the entry with path p.
+func (t *TreeFS) formatGitStatus(p string) string {
+	status := t.gitStatus(p)
+	if status == "" {
+		return "  "
+	}
+	return status
+}
@@ -0,0 +1,80 @@
+// Package gitstatus provides the git status of the entries of a git working
+// tree, for use with treefs.WithGitStatus.
+//
+// It shells out to the git binary, which must be on PATH.
+package gitstatus
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// Status maps the slash-separated paths of a working tree, relative to the
+// directory it was loaded from, to their XY status codes.
+type Status map[string]string
+
+// Load returns the Status of the working tree containing the directory dir,
+// including untracked and ignored entries, with paths relative to dir.
+func Load(dir string) (Status, error) {
+	prefix, err := git(dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+	prefix = strings.TrimSpace(prefix)
+
+	out, err := git(dir, "status", "--porcelain=v1", "-z", "--ignored", "--", ".")
+	if err != nil {
+		return nil, err
+	}
+
+	status := make(Status)
+	records := strings.Split(out, "\x00")
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if len(record) < 4 {
+			continue
+		}
+		xy, p := record[:2], record[3:]
+		// Renames and copies are followed by a record holding the original
+		// path, which is skipped.
+		if xy[0] == 'R' || xy[0] == 'C' {
+			i++
+		}
+
+		p = strings.TrimPrefix(p, prefix)
+		status[strings.TrimSuffix(p, "/")] = xy
+	}
+	return status, nil
+}
+
+// Of returns the status of the entry with path p, or an empty string if it
+// is unmodified.
+//
+// Entries inside an untracked or ignored directory, which git reports as a
+// whole, inherit the directory's status. The signature of Of matches
+// treefs.GitStatusFunc.
+func (s Status) Of(p string) string {
+	for p = path.Clean(p); ; p = path.Dir(p) {
+		if xy, ok := s[p]; ok {
+			return xy
+		}
+		if p == "." || p == "/" {
+			return ""
+		}
+	}
+}
+
+// Run git with the arguments args in the directory dir.
+func git(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("gitstatus: git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,61 @@
+package gitstatus
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not found")
+	}
+
+	repo := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	write := func(name, data string) {
+		t.Helper()
+		p := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q")
+	write("sub/tracked.txt", "a")
+	write("sub/clean.txt", "a")
+	write(".gitignore", "*.log\n")
+	run("add", ".")
+	run("-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "-m", "init")
+
+	write("sub/tracked.txt", "b")
+	write("sub/new/untracked.txt", "a")
+	write("sub/debug.log", "a")
+
+	status, err := Load(filepath.Join(repo, "sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for p, expected := range map[string]string{
+		"tracked.txt":       " M",
+		"clean.txt":         "",
+		"new":               "??",
+		"new/untracked.txt": "??",
+		"debug.log":         "!!",
+	} {
+		if got := status.Of(p); got != expected {
+			t.Errorf("%s: expected %q, got %q", p, expected, got)
+		}
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/Algebra8/treefs
 
-go 1.18
+go 1.25
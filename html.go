@@ -0,0 +1,90 @@
+package treefs
+
+import (
+	"html"
+	"html/template"
+	"net/url"
+	"strings"
+)
+
+// htmlPage is the template of the page rendered by HTML.
+var htmlPage = template.Must(template.New("html").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<pre>
+{{.Graph}}</pre>
+<hr>
+<p>{{.Meta}}</p>
+</body>
+</html>
+`))
+
+// HTML returns an HTML page with the title title graphing the TreeFS t, like
+// `tree -H baseHREF -T title`, with every entry linked to baseHREF followed by
+// its path relative to its root.
+//
+// Directories are linked with a trailing slash, so that the page can be
+// served alongside the listed files, e.g. on a static site.
+func (t TreeFS) HTML(baseHREF, title string) string {
+	baseHREF = strings.TrimSuffix(baseHREF, "/")
+
+	var graph strings.Builder
+	for _, root := range t.roots {
+		href := baseHREF
+		if root.IsDir {
+			href += "/"
+		}
+		graph.WriteString(htmlLink(href, root.Name, "") + "\n")
+		t.writeHTML(&graph, root, root.Children, "", baseHREF)
+	}
+
+	var b strings.Builder
+	// Executing the template with strings can't fail.
+	_ = htmlPage.Execute(&b, map[string]any{
+		"Title": title,
+		"Graph": template.HTML(graph.String()),
+		"Meta":  t.Meta(),
+	})
+	return b.String()
+}
+
+// Recursively write the lines of the entries, and their children, of the
+// root Node root to b, with each line preceded by prefix.
+func (t *TreeFS) writeHTML(b *strings.Builder, root *Node, entries []*Node, prefix, baseHREF string) {
+	for i, child := range entries {
+		connector, childPrefix := t.style.Tee, prefix+t.style.Pipe
+		if i == len(entries)-1 {
+			connector, childPrefix = t.style.Elbow, prefix+t.style.Space
+		}
+
+		rel := child.Path
+		if root.Path != "." {
+			rel = strings.TrimPrefix(rel, root.Path+"/")
+		}
+		href := baseHREF
+		for _, elem := range strings.Split(rel, "/") {
+			href += "/" + url.PathEscape(elem)
+		}
+		if child.IsDir {
+			href += "/"
+		}
+
+		b.WriteString(prefix + connector + " " + htmlLink(href, child.Name, t.info(child)) + "\n")
+		t.writeHTML(b, root, child.Children, childPrefix, baseHREF)
+	}
+}
+
+// Return the anchor linking name to href, preceded by the info of the entry,
+// if any.
+func htmlLink(href, name, info string) string {
+	s := `<a href="` + html.EscapeString(href) + `">` + html.EscapeString(name) + "</a>"
+	if info != "" {
+		s = html.EscapeString(info) + "  " + s
+	}
+	return s
+}
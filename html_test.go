@@ -0,0 +1,66 @@
+package treefs
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestHTML(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"docs/a b.md":     {Data: make([]byte, 12)},
+		"docs/<x>.md":     {},
+		"src/main.go":     {},
+		"src/util/str.go": {},
+	}
+
+	tfs, err := New(mapfs, "src", Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Sources &amp; docs</title>
+</head>
+<body>
+<h1>Sources &amp; docs</h1>
+<pre>
+<a href="https://example.com/src/">src</a>
+├── [          0]  <a href="https://example.com/src/main.go">main.go</a>
+└── [          0]  <a href="https://example.com/src/util/">util</a>
+    └── [          0]  <a href="https://example.com/src/util/str.go">str.go</a>
+</pre>
+<hr>
+<p>1 directory, 2 files</p>
+</body>
+</html>
+`[1:]
+	compare(t, tfs.HTML("https://example.com/src/", "Sources & docs"), expected)
+
+	tfs, err = New(mapfs, "docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>docs</title>
+</head>
+<body>
+<h1>docs</h1>
+<pre>
+<a href="/">docs</a>
+├── <a href="/%3Cx%3E.md">&lt;x&gt;.md</a>
+└── <a href="/a%20b.md">a b.md</a>
+</pre>
+<hr>
+<p>0 directories, 2 files</p>
+</body>
+</html>
+`[1:]
+	compare(t, tfs.HTML("", "docs"), expected)
+}
@@ -0,0 +1,253 @@
+package treefs
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// handlerPage is the template of the HTML served by Handler.
+var handlerPage = template.Must(template.New("handler").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}}</title>
+</head>
+<body>
+<pre>{{.Graph}}</pre>
+<p>{{.Meta}}</p>
+</body>
+</html>
+`))
+
+// defaultQueryParams are the query parameters that the clients of Handler
+// may set. "hidden" is left out, so that clients can't reveal the entries,
+// such as .env files, that the server hides.
+var defaultQueryParams = []string{"level", "dirsonly", "minsize", "maxsize"}
+
+// Handler returns an http.Handler that serves the tree of fsys, walked on
+// every request with the Opts opts, for quick admin or debug endpoints.
+//
+// The response is plain text, JSON in the format of `tree -J`, or HTML,
+// negotiated through the Accept header or forced with the "format" query
+// parameter ("text", "json" or "html"). The root is set with the "path" query
+// parameter, "." by default, and requests for a root that opts hide, e.g.
+// with Ignore, fail with a 404 status like missing ones. Clients may also set the following query
+// parameters, see HandlerWithParams to allow others:
+//
+//	level    see Level
+//	dirsonly see DirOnly, when "true" or "1"
+//	minsize  see MinSize
+//	maxsize  see MaxSize
+//
+// If the graph exceeds the limits set by MaxOutputBytes or MaxNodes, it is
+// served truncated as text or HTML, while JSON requests fail with a 422
+// status, since a truncated JSON document would be invalid.
+func Handler(fsys fs.FS, opts ...Opt) http.Handler {
+	return HandlerWithParams(fsys, defaultQueryParams, opts...)
+}
+
+// HandlerWithParams is like Handler, but only allows clients to set the
+// query parameters params, besides "format" and "path". Those supported are
+// the ones of Handler and:
+//
+//	hidden   see Hidden, when "true" or "1"
+//
+// Requests setting any other query parameter fail with a 400 status.
+func HandlerWithParams(fsys fs.FS, params []string, opts ...Opt) http.Handler {
+	allowed := map[string]bool{"format": true, "path": true}
+	for _, param := range params {
+		allowed[param] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		for param := range q {
+			if !allowed[param] {
+				http.Error(w, fmt.Sprintf("query parameter %q not allowed", param), http.StatusBadRequest)
+				return
+			}
+		}
+
+		name := q.Get("path")
+		if name == "" {
+			name = "."
+		}
+
+		reqOpts, err := queryOpts(q.Get)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		tfs, err := New(fsys, name, append(opts[:len(opts):len(opts)], reqOpts...)...)
+		var outputErr *OutputLimitError
+		var nodesErr *NodeLimitError
+		switch {
+		case errors.As(err, &outputErr), errors.As(err, &nodesErr):
+			// Serve the truncated graph, which ends with a notice.
+		case errors.Is(err, ErrInvalidName):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, fs.ErrNotExist):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !tfs.reachable(name) {
+			// Don't reveal whether an entry hidden by opts exists.
+			err := &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		format := q.Get("format")
+		if format == "" {
+			format = negotiate(r.Header.Get("Accept"))
+		}
+		switch format {
+		case "json":
+			if err != nil {
+				// The JSON of the Nodes isn't bounded by the limits.
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintln(w, tfs.JSON())
+		case "html":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_ = handlerPage.Execute(w, map[string]string{
+				"Name":  name,
+				"Graph": tfs.Graph(),
+				"Meta":  tfs.Meta(),
+			})
+		case "text":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			fmt.Fprintln(w, tfs)
+		default:
+			http.Error(w, fmt.Sprintf("unknown format %q", format), http.StatusBadRequest)
+		}
+	})
+}
+
+// Report whether the entry with path p would be displayed when walking the
+// fs.FS of t from its root, i.e. whether every element of p passes the
+// hidden, Ignore, IgnoreFile and IgnoreMatcher filters of t, and, for a
+// file, its file filters.
+func (t *TreeFS) reachable(p string) bool {
+	if p == "." {
+		return true
+	}
+
+	dir := &Node{Name: ".", Path: ".", IsDir: true}
+	for i, elem := range strings.Split(p, "/") {
+		fi, err := fs.Stat(t.fsys, path.Join(dir.Path, elem))
+		if err != nil {
+			return false
+		}
+		entry := fs.FileInfoToDirEntry(fi)
+
+		dir.ignore = t.ignorePatterns(dir)
+		if !t.allow(entry) || matchAny(dir.ignore, elem, t.ignoreCase) {
+			return false
+		}
+		child := &Node{
+			Name:   elem,
+			Path:   path.Join(dir.Path, elem),
+			IsDir:  fi.IsDir(),
+			Info:   fi,
+			entry:  entry,
+			depth:  i + 1,
+			ignore: dir.ignore,
+		}
+		if t.ignored(child.Path, entry) || !child.IsDir && !t.allowFile(child) {
+			return false
+		}
+		dir = child
+	}
+	return true
+}
+
+// Return the Opts for the query parameters of a Handler request, looked up
+// with get.
+func queryOpts(get func(string) string) (opts []Opt, err error) {
+	parseBool := func(key string) bool {
+		v := get(key)
+		return v == "true" || v == "1"
+	}
+	parseInt := func(key string) (n int64, ok bool, err error) {
+		v := get(key)
+		if v == "" {
+			return 0, false, nil
+		}
+		if n, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return 0, false, fmt.Errorf("invalid %s %q", key, v)
+		}
+		return n, true, nil
+	}
+
+	if parseBool("hidden") {
+		opts = append(opts, Hidden)
+	}
+	if parseBool("dirsonly") {
+		opts = append(opts, DirOnly)
+	}
+
+	if n, ok, err := parseInt("level"); err != nil {
+		return nil, err
+	} else if ok {
+		opts = append(opts, Level(int(n)))
+	}
+	if n, ok, err := parseInt("minsize"); err != nil {
+		return nil, err
+	} else if ok {
+		opts = append(opts, MinSize(n))
+	}
+	if n, ok, err := parseInt("maxsize"); err != nil {
+		return nil, err
+	} else if ok {
+		opts = append(opts, MaxSize(n))
+	}
+
+	return opts, nil
+}
+
+// Return the format, "text", "json" or "html", of the media range of the
+// Accept header accept with the highest quality value that Handler supports,
+// the first one if several are equally preferred, or "text" if none is.
+func negotiate(accept string) string {
+	format, best := "text", 0.0
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q <= best {
+			continue
+		}
+
+		switch mediaType {
+		case "application/json":
+			format, best = "json", q
+		case "text/html":
+			format, best = "html", q
+		case "text/plain", "text/*", "*/*":
+			format, best = "text", q
+		}
+	}
+	return format
+}
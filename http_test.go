@@ -0,0 +1,207 @@
+package treefs
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestHandler(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a1.test":       {Data: make([]byte, 10)},
+		"b/b1.test":     {Data: make([]byte, 100)},
+		"b/c/.c1.test":  {},
+		"b/c/d/d1.test": {},
+	}
+	srv := httptest.NewServer(Handler(mapfs))
+	defer srv.Close()
+
+	tests := []struct {
+		query       string
+		accept      string
+		status      int
+		contentType string
+		expected    string
+	}{
+		{
+			query:       "?level=1",
+			status:      http.StatusOK,
+			contentType: "text/plain; charset=utf-8",
+			expected:    ".\n├── a1.test\n└── b\n\n1 directory, 1 file\n",
+		},
+		{
+			query:       "?path=b&minsize=50",
+			accept:      "application/json",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			expected: `{"type":"file","name":"b1.test"}` + "\n  ]}\n,\n" +
+				`  {"type":"report","directories":0,"files":1}` + "\n]\n",
+		},
+		{
+			query:       "?path=b/c&format=html",
+			status:      http.StatusOK,
+			contentType: "text/html; charset=utf-8",
+			expected:    "<pre>b/c\n└── d\n    └── d1.test</pre>",
+		},
+		{
+			query:  "?path=b/c&hidden=1",
+			status: http.StatusBadRequest,
+		},
+		{
+			query:       "?level=1",
+			accept:      "text/html;q=0, application/json",
+			status:      http.StatusOK,
+			contentType: "application/json",
+		},
+		{
+			query:  "?path=missing",
+			status: http.StatusNotFound,
+		},
+		{
+			query:  "?level=x",
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+tc.query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.accept != "" {
+			req.Header.Set("Accept", tc.accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tc.status {
+			t.Fatalf("%s: expected status %d, got %d: %s", tc.query, tc.status, resp.StatusCode, body)
+		}
+		if tc.contentType != "" && resp.Header.Get("Content-Type") != tc.contentType {
+			t.Fatalf("%s: expected content type %q, got %q", tc.query, tc.contentType, resp.Header.Get("Content-Type"))
+		}
+		if !strings.Contains(string(body), tc.expected) {
+			t.Fatalf("%s: expected body to contain %q, got %q", tc.query, tc.expected, body)
+		}
+	}
+}
+
+func TestHandlerWithParams(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a1.test":      {},
+		"b/c/.c1.test": {},
+		"b/c/d1.test":  {},
+	}
+	srv := httptest.NewServer(HandlerWithParams(mapfs, []string{"hidden"}, MaxNodes(2)))
+	defer srv.Close()
+
+	tests := []struct {
+		query    string
+		status   int
+		expected string
+	}{
+		{
+			query:    "?path=b/c&hidden=1",
+			status:   http.StatusOK,
+			expected: "b/c\n├── .c1.test\n└── d1.test\n",
+		},
+		{
+			// The truncated graph is served.
+			query:    "?hidden=true",
+			status:   http.StatusOK,
+			expected: ".\n├── a1.test\n└── b\n[output truncated at 2 entries]\n",
+		},
+		{
+			query:  "?hidden=true&format=json",
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			query:    "?path=b/c&format=json",
+			status:   http.StatusOK,
+			expected: `{"type":"file","name":"d1.test"}`,
+		},
+		{
+			query:  "?level=1",
+			status: http.StatusBadRequest,
+		},
+	}
+	for _, tc := range tests {
+		resp, err := http.Get(srv.URL + tc.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tc.status {
+			t.Fatalf("%s: expected status %d, got %d: %s", tc.query, tc.status, resp.StatusCode, body)
+		}
+		if !strings.Contains(string(body), tc.expected) {
+			t.Fatalf("%s: expected body to contain %q, got %q", tc.query, tc.expected, body)
+		}
+	}
+}
+
+func TestHandlerHiddenPath(t *testing.T) {
+	mapfs := fstest.MapFS{
+		".secret/key":       {},
+		"ignored/a1.test":   {},
+		"b/.gitignore":      {Data: []byte("c\n")},
+		"b/c/c1.test":       {},
+		"b/d/small.test":    {Data: make([]byte, 10)},
+		"b/d/sub/sub1.test": {},
+		"visible/a2.test":   {},
+	}
+	tests := []struct {
+		handler http.Handler
+		query   string
+		status  int
+	}{
+		{Handler(mapfs), "?path=.secret", http.StatusNotFound},
+		{Handler(mapfs), "?path=.secret/key", http.StatusNotFound},
+		{Handler(mapfs, Ignore("ignored")), "?path=ignored", http.StatusNotFound},
+		{Handler(mapfs, Ignore("ignored")), "?path=ignored/a1.test", http.StatusNotFound},
+		{Handler(mapfs, IgnoreFile(".gitignore"), Hidden), "?path=b/c", http.StatusNotFound},
+		{Handler(mapfs, MinSize(5)), "?path=b/d/sub/sub1.test", http.StatusNotFound},
+		{Handler(mapfs, MinSize(5)), "?path=b/d/small.test", http.StatusOK},
+		{Handler(mapfs, Ignore("ignored")), "?path=visible", http.StatusOK},
+		{HandlerWithParams(mapfs, []string{"hidden"}), "?path=.secret&hidden=1", http.StatusOK},
+	}
+	for _, tc := range tests {
+		rec := httptest.NewRecorder()
+		tc.handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+tc.query, nil))
+		if rec.Code != tc.status {
+			t.Errorf("%s: expected status %d, got %d: %s", tc.query, tc.status, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	for accept, expected := range map[string]string{
+		"":                                  "text",
+		"application/json":                  "json",
+		"text/html, application/json":       "html",
+		"text/html;q=0, application/json":   "json",
+		"text/html;q=0.5, application/json": "json",
+		"application/json;q=0.8, */*;q=0.9": "text",
+		"text/html;q=0":                     "text",
+		"image/png, text/html;q=0.1":        "html",
+		"text/html;q=x, application/json":   "json",
+	} {
+		if got := negotiate(accept); got != expected {
+			t.Errorf("%q: expected %q, got %q", accept, expected, got)
+		}
+	}
+}
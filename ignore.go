@@ -0,0 +1,121 @@
+package treefs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// IgnoreMatcher reports whether the entry d, with the slash-separated path
+// path in the fs.FS, should be hidden, so that gitignore libraries,
+// deny-lists or policy engines can decide what is displayed.
+type IgnoreMatcher interface {
+	Match(path string, d fs.DirEntry) bool
+}
+
+// IgnoreMatcherFunc is an adapter to allow the use of ordinary functions as
+// IgnoreMatchers.
+type IgnoreMatcherFunc func(path string, d fs.DirEntry) bool
+
+// Match calls f(path, d).
+func (f IgnoreMatcherFunc) Match(path string, d fs.DirEntry) bool {
+	return f(path, d)
+}
+
+// WithIgnoreMatcher hides the entries that m matches, along with their
+// entries. Hidden directories are not walked.
+//
+// It can be applied several times, an entry being hidden if any of the
+// IgnoreMatchers match it.
+func WithIgnoreMatcher(m IgnoreMatcher) Opt {
+	return func(t *TreeFS) {
+		// Ignore if m is nil.
+		if m == nil {
+			return
+		}
+		t.ignoreMatchers = append(t.ignoreMatchers, m)
+	}
+}
+
+// IgnoreFile reads Ignore patterns from the file with name name, e.g.
+// ".treeignore", in each directory that is walked. Its patterns hide the
+// entries of that directory and of its subdirectories, independently of any
+// .gitignore.
+//
+// Patterns are read one per line, with the syntax of Ignore. Blank lines and
+// lines starting with "#" are skipped.
+func IgnoreFile(name string) Opt {
+	return func(t *TreeFS) {
+		t.ignoreFile = name
+	}
+}
+
+// IgnoreFrom reads Ignore patterns from r, in the format of IgnoreFile. r is
+// read once, when IgnoreFrom is called, so the Opt can be reused.
+//
+// If r can't be read, New returns the error.
+func IgnoreFrom(r io.Reader) Opt {
+	patterns, err := readPatterns(r)
+	if err != nil {
+		err = fmt.Errorf("treefs: reading ignore patterns: %w", err)
+	}
+	return func(t *TreeFS) {
+		if err != nil {
+			t.optErr = errors.Join(t.optErr, err)
+		}
+		t.ignore = append(t.ignore, patterns...)
+	}
+}
+
+// Read the patterns of r, one per line, skipping blank lines and comments.
+func readPatterns(r io.Reader) (patterns []string, err error) {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, s.Err()
+}
+
+// Return the patterns that hide entries of the directory Node n: those
+// inherited from its ancestors and those of its IgnoreFile, if any.
+func (t *TreeFS) ignorePatterns(n *Node) []string {
+	patterns := n.ignore
+	if t.ignoreFile == "" {
+		return patterns
+	}
+
+	f, err := t.fsys.Open(path.Join(n.Path, t.ignoreFile))
+	if err != nil {
+		if t.strict && !errors.Is(err, fs.ErrNotExist) {
+			t.fail("open", path.Join(n.Path, t.ignoreFile), err)
+		}
+		return patterns
+	}
+	defer f.Close()
+
+	own, err := readPatterns(f)
+	if err != nil && t.strict {
+		t.fail("read", path.Join(n.Path, t.ignoreFile), err)
+	}
+	// Don't share the backing array of the parent's patterns with siblings.
+	return append(patterns[:len(patterns):len(patterns)], own...)
+}
+
+// Report whether the entry with path p is hidden by any of the
+// IgnoreMatchers of t.
+func (t *TreeFS) ignored(p string, d fs.DirEntry) bool {
+	for _, m := range t.ignoreMatchers {
+		if m.Match(p, d) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package treefs
+
+import (
+	"errors"
+	"io/fs"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestIgnoreFile(t *testing.T) {
+	mapfs := fstest.MapFS{
+		".treeignore":   {Data: []byte("# build output\n*.o\n\ndist\n")},
+		"a/.treeignore": {Data: []byte("*.tmp")},
+		"a/a1.o":        {},
+		"a/a1.tmp":      {},
+		"a/b/b1.tmp":    {},
+		"a/b/b2.test":   {},
+		"c/c1.tmp":      {},
+		"dist/d1.test":  {},
+		"main.go":       {},
+	}
+
+	tfs, err := New(mapfs, ".", IgnoreFile(".treeignore"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, tfs.String(), `
+.
+├── a
+│   └── b
+│       └── b2.test
+├── c
+│   └── c1.tmp
+└── main.go
+
+3 directories, 3 files`[1:])
+}
+
+func TestIgnoreFrom(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.log":    {},
+		"a/a1.test":   {},
+		"a/b/b1.test": {},
+	}
+
+	opt := IgnoreFrom(strings.NewReader("*.log\n  b  \n"))
+	for i := 0; i < 2; i++ {
+		tfs, err := New(mapfs, "a", opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		compare(t, tfs.Graph(), `
+a
+└── a1.test`[1:])
+	}
+
+	if _, err := New(mapfs, "a", IgnoreFrom(failReader{})); !errors.Is(err, errRead) {
+		t.Fatalf("expected %v, got %v", errRead, err)
+	}
+}
+
+var errRead = errors.New("read failed")
+
+type failReader struct{}
+
+func (failReader) Read([]byte) (int, error) { return 0, errRead }
+
+func TestWithIgnoreMatcher(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.test":       {},
+		"a/b/b1.test":     {},
+		"a/secret/s.test": {},
+	}
+
+	var paths []string
+	record := IgnoreMatcherFunc(func(p string, d fs.DirEntry) bool {
+		paths = append(paths, p)
+		return false
+	})
+	deny := IgnoreMatcherFunc(func(p string, d fs.DirEntry) bool {
+		return p == "a/secret" || p == "a/b/b1.test"
+	})
+
+	tfs, err := New(mapfs, "a", WithIgnoreMatcher(record), WithIgnoreMatcher(deny), WithIgnoreMatcher(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, tfs.Graph(), `
+a
+├── a1.test
+└── b`[1:])
+
+	expected := []string{"a/a1.test", "a/b", "a/b/b1.test", "a/secret"}
+	if !slices.Equal(paths, expected) {
+		t.Errorf("expected matched paths %v, got %v", expected, paths)
+	}
+}
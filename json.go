@@ -0,0 +1,213 @@
+package treefs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// jsonEntry is an element of the JSON document produced by `tree -J`.
+//
+// The report element shares the same shape, with only Type, Directories and
+// Files set.
+type jsonEntry struct {
+	Type     string      `json:"type"`
+	Name     string      `json:"name"`
+	Target   string      `json:"target,omitempty"`
+	Mode     string      `json:"mode,omitempty"`
+	Size     *int64      `json:"size,omitempty"`
+	Time     string      `json:"time,omitempty"`
+	Error    string      `json:"error,omitempty"`
+	Contents []jsonEntry `json:"contents,omitempty"`
+
+	Directories int `json:"directories,omitempty"`
+	Files       int `json:"files,omitempty"`
+}
+
+// Layouts that the "time" of `tree -J` entries are parsed with: those of
+// `tree -D` and RFC 3339, a common `--timefmt`.
+var jsonTimeLayouts = []string{
+	"Jan _2  2006",
+	"Jan _2 15:04",
+	time.RFC3339,
+}
+
+// ReadJSON reads the JSON document produced by `tree -J` from r and returns
+// the Node of each of its roots.
+//
+// The metadata of each entry, such as its size, mode and modification time,
+// is exposed through the Node's Info when present in the document. The
+// trailing report is ignored since it can be recomputed from the Nodes.
+func ReadJSON(r io.Reader) ([]*Node, error) {
+	var entries []jsonEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("treefs: decoding tree JSON: %w", err)
+	}
+
+	var roots []*Node
+	for _, entry := range entries {
+		if entry.Type == "report" {
+			continue
+		}
+		root, err := entry.node("")
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, root)
+	}
+	return roots, nil
+}
+
+// Convert e, whose parent has the path dir, to a Node.
+func (e jsonEntry) node(dir string) (*Node, error) {
+	n := &Node{
+		Name:   e.Name,
+		Path:   path.Join(dir, e.Name),
+		IsDir:  e.Type == "directory",
+		Target: e.Target,
+	}
+
+	mode, ok := jsonTypes[e.Type]
+	if !ok {
+		mode = fs.ModeIrregular
+	}
+	info := nodeInfo{name: e.Name, mode: mode}
+	if e.Mode != "" {
+		perm, err := strconv.ParseUint(e.Mode, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("treefs: invalid mode %q of %s: %w", e.Mode, n.Path, err)
+		}
+		info.mode |= fs.FileMode(perm) & fs.ModePerm
+		if perm&0o4000 != 0 {
+			info.mode |= fs.ModeSetuid
+		}
+		if perm&0o2000 != 0 {
+			info.mode |= fs.ModeSetgid
+		}
+		if perm&0o1000 != 0 {
+			info.mode |= fs.ModeSticky
+		}
+	}
+	if e.Size != nil {
+		info.size = *e.Size
+	}
+	for _, layout := range jsonTimeLayouts {
+		if t, err := time.ParseInLocation(layout, e.Time, time.Local); err == nil {
+			info.modTime = t
+			break
+		}
+	}
+	n.Info = info
+
+	for _, child := range e.Contents {
+		c, err := child.node(n.Path)
+		if err != nil {
+			return nil, err
+		}
+		n.Children = append(n.Children, c)
+	}
+	return n, nil
+}
+
+// The fs.FileMode type bits of each `tree -J` entry type.
+var jsonTypes = map[string]fs.FileMode{
+	"directory": fs.ModeDir,
+	"file":      0,
+	"link":      fs.ModeSymlink,
+	"fifo":      fs.ModeNamedPipe,
+	"socket":    fs.ModeSocket,
+	"char":      fs.ModeDevice | fs.ModeCharDevice,
+	"block":     fs.ModeDevice,
+}
+
+// JSON returns the JSON document of the TreeFS t, as produced by `tree -J`:
+// an array with the nested entries of each root, followed by a report with
+// the number of directories and files.
+//
+// Entries include their size and modification time if Size, HumanSize or
+// FixedSize, and ShowMTime, were applied.
+func (t TreeFS) JSON() string {
+	var b strings.Builder
+	b.WriteString("[")
+	for i, root := range t.roots {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+		t.writeJSON(&b, root, 1)
+	}
+
+	report := fmt.Sprintf(`{"type":"report","directories":%d`, t.NDirs)
+	if !t.dirOnly {
+		report += fmt.Sprintf(`,"files":%d`, t.NFiles+t.NIrregular+t.NSymlinks)
+	}
+	b.WriteString("\n,\n  " + report + "}\n]")
+	return b.String()
+}
+
+// Write the JSON object of the Node n, and its children, to b, indented as
+// at depth depth.
+func (t *TreeFS) writeJSON(b *strings.Builder, n *Node, depth int) {
+	e := jsonEntry{
+		Type:   entryType(n.mode()),
+		Name:   n.Name,
+		Target: n.Target,
+	}
+	if t.sizeFmt != nil || t.mtime {
+		if fi, _ := n.info(); fi != nil {
+			if t.sizeFmt != nil {
+				size := fi.Size()
+				e.Size = &size
+			}
+			if t.mtime {
+				e.Time = t.formatTime(fi, modTime)
+			}
+		}
+	}
+	// Marshaling strings, and structs of them, can't fail.
+	obj, _ := json.Marshal(e)
+
+	indent := strings.Repeat("  ", depth)
+	b.WriteString(indent)
+	if !n.IsDir {
+		b.Write(obj)
+		return
+	}
+
+	b.Write(obj[:len(obj)-1])
+	b.WriteString(`,"contents":[`)
+	for i, child := range n.Children {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+		t.writeJSON(b, child, depth+1)
+	}
+	b.WriteString("\n" + indent + "]}")
+}
+
+// Return the `tree -J` and `tree -X` type of an entry with the mode mode.
+func entryType(mode fs.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return "directory"
+	case mode&fs.ModeSymlink != 0:
+		return "link"
+	case mode&fs.ModeNamedPipe != 0:
+		return "fifo"
+	case mode&fs.ModeSocket != 0:
+		return "socket"
+	case mode&fs.ModeCharDevice != 0:
+		return "char"
+	case mode&fs.ModeDevice != 0:
+		return "block"
+	case mode&fs.ModeIrregular != 0:
+		return "unknown"
+	}
+	return "file"
+}
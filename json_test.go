@@ -0,0 +1,94 @@
+package treefs
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestReadJSON(t *testing.T) {
+	// Output of `tree -J -p -s testdata/a/b` from directory containing
+	// testdata/, with a symlink added.
+	doc := `
+[
+  {"type":"directory","name":"testdata/a/b","mode":"0775","prot":"drwxrwxr-x","size":4096,"contents":[
+    {"type":"file","name":"b1.test","mode":"0664","prot":"-rw-rw-r--","size":12},
+    {"type":"link","name":"b2.test","target":"b1.test","mode":"0777","prot":"lrwxrwxrwx","size":7},
+    {"type":"directory","name":"d","mode":"0775","prot":"drwxrwxr-x","size":4096,"contents":[
+      {"type":"file","name":"d1.test","mode":"0664","prot":"-rw-rw-r--","size":0}
+    ]}
+  ]}
+,
+  {"type":"report","directories":1,"files":3}
+]`
+
+	roots, err := ReadJSON(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roots) != 1 {
+		t.Fatalf("expected 1 root, got %d", len(roots))
+	}
+
+	root := roots[0]
+	if !root.IsDir || root.Path != "testdata/a/b" || len(root.Children) != 3 {
+		t.Fatalf("unexpected root %+v", root)
+	}
+
+	b1, b2, d := root.Children[0], root.Children[1], root.Children[2]
+	if b1.Path != "testdata/a/b/b1.test" || b1.Info.Size() != 12 || b1.Info.Mode() != 0o664 {
+		t.Fatalf("unexpected file %+v (mode %v)", b1, b1.Info.Mode())
+	}
+	if b2.Target != "b1.test" || b2.Info.Mode()&fs.ModeSymlink == 0 {
+		t.Fatalf("unexpected link %+v", b2)
+	}
+	if !d.IsDir || len(d.Children) != 1 || d.Children[0].Path != "testdata/a/b/d/d1.test" {
+		t.Fatalf("unexpected directory %+v", d)
+	}
+
+	if _, err := ReadJSON(strings.NewReader("{")); err == nil {
+		t.Fatal("expected error for malformed JSON")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	tfs, err := New(testFS, "testdata/a/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Output of `tree -J testdata/a/b` from directory containing testdata/.
+	expected := `
+[
+  {"type":"directory","name":"testdata/a/b","contents":[
+    {"type":"file","name":"b1.test"},
+    {"type":"file","name":"b2.test"},
+    {"type":"file","name":"b3.test"},
+    {"type":"directory","name":"d","contents":[
+      {"type":"file","name":"d1.test"}
+    ]}
+  ]}
+,
+  {"type":"report","directories":1,"files":4}
+]`[1:]
+	compare(t, tfs.JSON(), expected)
+
+	roots, err := ReadJSON(strings.NewReader(tfs.JSON()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roots) != 1 || len(roots[0].Children) != 4 || roots[0].Children[3].Children[0].Path != "testdata/a/b/d/d1.test" {
+		t.Fatalf("unexpected roots read back from JSON: %+v", roots)
+	}
+
+	tfs, err = New(testFS, "testdata/a", DirOnly, Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(tfs.JSON(), `{"type":"report","directories":3}`+"\n]") {
+		t.Fatalf("expected a report without files, got:\n%s", tfs.JSON())
+	}
+	if !strings.Contains(tfs.JSON(), `{"type":"directory","name":"d","size":`) {
+		t.Fatalf("expected sizes, got:\n%s", tfs.JSON())
+	}
+}
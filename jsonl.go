@@ -0,0 +1,63 @@
+package treefs
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// jsonLine is a line written by JSONLines.
+type jsonLine struct {
+	Type  string `json:"type"`
+	Name  string `json:"name,omitempty"`
+	Path  string `json:"path"`
+	Depth int    `json:"depth"`
+	Size  *int64 `json:"size,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// JSONLines writes every entry to w as it is discovered, as a JSON object on
+// a line of its own, so that huge trees can be piped into log processors:
+//
+//	{"type":"directory","name":"b","path":"a/b","depth":1}
+//	{"type":"file","name":"b1.test","path":"a/b/b1.test","depth":2,"size":12}
+//
+// Sizes are included if Size, HumanSize or FixedSize were applied, and
+// failures to read directories are written with the "error" type.
+//
+// The walked Nodes are still kept to render the graph, even with OnLine.
+// Combine with Stream, e.g. to io.Discard, so that neither the graph nor the
+// Nodes are kept in memory, in which case the entries of each directory are
+// written before those of its subdirectories.
+//
+// If writing to w fails, the walk stops and New returns the error.
+func JSONLines(w io.Writer) Opt {
+	return func(t *TreeFS) {
+		t.jsonLines = json.NewEncoder(w)
+	}
+}
+
+// Write the JSON line of the Event ev for the Node n, if the JSONLines Opt
+// was applied.
+func (t *TreeFS) writeJSONLine(ev Event, n *Node) error {
+	if t.jsonLines == nil {
+		return nil
+	}
+
+	line := jsonLine{Name: n.Name, Path: ev.Path, Depth: ev.Depth}
+	switch ev.Kind {
+	case EnterDir, File:
+		line.Type = entryType(n.mode())
+		if t.sizeFmt != nil && n.depth > 0 {
+			if fi, _ := n.info(); fi != nil {
+				size := fi.Size()
+				line.Size = &size
+			}
+		}
+	case Error:
+		line.Type = "error"
+		line.Error = ev.Err.Error()
+	default:
+		return nil
+	}
+	return t.jsonLines.Encode(line)
+}
@@ -0,0 +1,68 @@
+package treefs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/fstest"
+)
+
+func TestJSONLines(t *testing.T) {
+	fsys := errFS{
+		MapFS: fstest.MapFS{
+			"a/a1.test":   {Data: make([]byte, 12)},
+			"a/b/b1.test": {},
+			"a/c/c1.test": {},
+		},
+		dirs: map[string]bool{"a/c": true},
+	}
+
+	var buf bytes.Buffer
+	var lines []string
+	tfs, err := New(fsys, "a", Size, Strict, JSONLines(&buf), OnLine(func(line string) {
+		lines = append(lines, line)
+	}))
+	if !errors.Is(err, errUnreadable) {
+		t.Fatalf("expected %v, got %v", errUnreadable, err)
+	}
+	expected := `
+{"type":"directory","name":"a","path":"a","depth":0}
+{"type":"file","name":"a1.test","path":"a/a1.test","depth":1,"size":12}
+{"type":"directory","name":"b","path":"a/b","depth":1,"size":0}
+{"type":"file","name":"b1.test","path":"a/b/b1.test","depth":2,"size":0}
+{"type":"directory","name":"c","path":"a/c","depth":1,"size":0}
+{"type":"error","name":"c","path":"a/c","depth":1,"error":"readdir a/c: unreadable"}
+`[1:]
+	compare(t, buf.String(), expected)
+	if tfs.Len() != 0 || len(lines) != 5 {
+		t.Fatalf("expected the graph to only be passed to OnLine, got %d and %d lines", tfs.Len(), len(lines))
+	}
+
+	if _, err := New(fsys, "a", JSONLines(failWriter{})); !errors.Is(err, errWrite) {
+		t.Fatalf("expected %v, got %v", errWrite, err)
+	}
+}
+
+func TestJSONLinesStream(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/b/b1.test": {},
+		"a/z.test":    {},
+	}
+
+	var buf bytes.Buffer
+	tfs, err := New(mapfs, "a", JSONLines(&buf), Stream(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `
+{"type":"directory","name":"a","path":"a","depth":0}
+{"type":"file","name":"z.test","path":"a/z.test","depth":1}
+{"type":"directory","name":"b","path":"a/b","depth":1}
+{"type":"file","name":"b1.test","path":"a/b/b1.test","depth":2}
+`[1:]
+	compare(t, buf.String(), expected)
+	if root := tfs.Root(); len(root.Children) != 0 {
+		t.Errorf("expected the Nodes to be dropped, got %d children", len(root.Children))
+	}
+}
@@ -0,0 +1,25 @@
+package treefs
+
+import "io/fs"
+
+// FollowJunctions descends into Windows junctions and other directory
+// reparse points, which are otherwise listed but not walked since mount
+// point cycles would make the walk infinite.
+func FollowJunctions(t *TreeFS) {
+	t.followJunctions = true
+}
+
+// Report whether the directory Node n is a junction, or another reparse
+// point, rather than a plain directory.
+//
+// Since Go 1.23, os.DirFS reports such directories as irregular on Windows.
+// Older versions are covered by checking their file attributes.
+func (t *TreeFS) junction(n *Node) bool {
+	if !n.IsDir || n.entry == nil {
+		return false
+	}
+	if n.entry.Type()&fs.ModeIrregular != 0 {
+		return true
+	}
+	return reparsePoint(n)
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package treefs
+
+// Report whether the Node n is a reparse point, which only exist on Windows.
+func reparsePoint(n *Node) bool {
+	return false
+}
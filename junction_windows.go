@@ -0,0 +1,16 @@
+//go:build windows
+
+package treefs
+
+import "syscall"
+
+// Report whether the Node n has the FILE_ATTRIBUTE_REPARSE_POINT attribute,
+// as is the case of junctions.
+func reparsePoint(n *Node) bool {
+	fi, err := n.info()
+	if err != nil {
+		return false
+	}
+	d, ok := fi.Sys().(*syscall.Win32FileAttributeData)
+	return ok && d.FileAttributes&syscall.FILE_ATTRIBUTE_REPARSE_POINT != 0
+}
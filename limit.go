@@ -0,0 +1,161 @@
+package treefs
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// truncationNotice is the last line of a graph that exceeded MaxOutputBytes.
+const truncationNotice = "[output truncated at %d bytes]"
+
+// nodeLimitNotice is the last line of a graph that exceeded MaxNodes.
+const nodeLimitNotice = "[output truncated at %d entries]"
+
+// MaxOutputBytes stops rendering once the graph would exceed n bytes, so that
+// services embedding treefs can bound response sizes deterministically.
+//
+// The graph of a truncated TreeFS ends with a notice line, which is not
+// counted against n, and New returns it together with an *OutputLimitError.
+func MaxOutputBytes(n int) Opt {
+	return func(t *TreeFS) {
+		// Ignore if n <= 0.
+		if n <= 0 {
+			return
+		}
+		t.maxOutputBytes = n
+	}
+}
+
+// OutputLimitError is returned by New when the graph was truncated because it
+// exceeded the limit set by MaxOutputBytes.
+type OutputLimitError struct {
+	Limit int // the limit, in bytes, that was exceeded
+}
+
+// Error implements the error interface for OutputLimitError.
+func (e *OutputLimitError) Error() string {
+	return fmt.Sprintf("treefs: output exceeds %d bytes", e.Limit)
+}
+
+// MaxNodes stops rendering once n entries, not counting the root, have been
+// rendered, so that services rendering user-supplied fs.FS values can bound
+// their output regardless of the length of names.
+//
+// The graph of a truncated TreeFS ends with a notice line, and New returns it
+// together with a *NodeLimitError.
+func MaxNodes(n int) Opt {
+	return func(t *TreeFS) {
+		// Ignore if n <= 0.
+		if n <= 0 {
+			return
+		}
+		t.maxNodes = n
+	}
+}
+
+// NodeLimitError is returned by New when the graph was truncated because it
+// exceeded the limit set by MaxNodes.
+type NodeLimitError struct {
+	Limit int // the limit, in entries, that was exceeded
+}
+
+// Error implements the error interface for NodeLimitError.
+func (e *NodeLimitError) Error() string {
+	return fmt.Sprintf("treefs: output exceeds %d entries", e.Limit)
+}
+
+// Report whether rendering another entry would exceed the limit set by
+// MaxNodes, truncating the graph if so.
+func (t *TreeFS) nodeLimitReached() bool {
+	if t.maxNodes == 0 || t.nodes < t.maxNodes {
+		return false
+	}
+	t.truncated, t.nodeLimited = true, true
+	return true
+}
+
+// End the truncated graph of t with a notice, returning the error of the
+// limit it exceeded.
+func (t *TreeFS) truncate() error {
+	if t.nodeLimited {
+		t.output(Line{Name: fmt.Sprintf(nodeLimitNotice, t.maxNodes)})
+		return &NodeLimitError{Limit: t.maxNodes}
+	}
+	t.output(Line{Name: fmt.Sprintf(truncationNotice, t.maxOutputBytes)})
+	return &OutputLimitError{Limit: t.maxOutputBytes}
+}
+
+// Append the line to the tree t, unless doing so would exceed the limit set
+// by MaxOutputBytes.
+func (t *TreeFS) emit(line Line) {
+	if t.truncated {
+		return
+	}
+
+	size := len(line.String())
+	if t.outputLines > 0 {
+		size++ // the newline separating line from the previous one
+	}
+	if t.maxOutputBytes > 0 && t.outputBytes+size > t.maxOutputBytes {
+		t.truncated = true
+		return
+	}
+
+	t.outputBytes += size
+	t.output(line)
+}
+
+// Output the line, either to t's Stream writer, to its OnLine callback or to
+// the tree t.
+func (t *TreeFS) output(line Line) {
+	t.outputLines++
+	if t.stream != nil {
+		t.writeStream(line.String())
+		return
+	}
+	if t.onLine != nil {
+		t.onLine(line.String())
+		return
+	}
+	t.tree = append(t.tree, line)
+}
+
+// FileLimit lists, but doesn't descend into, directories with more than n
+// entries, like tree --filelimit, displaying their number of entries instead:
+//
+//	├── node_modules [1204 entries exceeds filelimit]
+//
+// The root is always walked. The entries of such directories are not counted
+// in the metadata.
+func FileLimit(n int) Opt {
+	return func(t *TreeFS) {
+		// Ignore if n <= 0.
+		if n <= 0 {
+			return
+		}
+		t.fileLimit = n
+	}
+}
+
+// Report whether the directory Node n, with the entries entries, exceeds the
+// limit set by FileLimit, recording its number of entries if so.
+func (t *TreeFS) exceedsFileLimit(n *Node, entries []fs.DirEntry) bool {
+	if t.fileLimit == 0 || n.depth == 0 || len(entries) <= t.fileLimit {
+		return false
+	}
+
+	count := 0
+	for _, entry := range entries {
+		if t.allow(entry) {
+			count++
+		}
+	}
+	if count <= t.fileLimit {
+		return false
+	}
+	if t.overLimit == nil {
+		t.overLimit = make(map[*Node]int)
+	}
+	t.overLimit[n] = count
+	return true
+}
@@ -0,0 +1,55 @@
+package treefs
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMaxOutputBytes(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a1.test":   {},
+		"b/b1.test": {},
+		"b/b2.test": {},
+	}
+
+	// ".\n├── a1.test\n├── b" is 1+18+12 = 31 bytes, as each connector is 9.
+	tfs, err := New(mapfs, ".", MaxOutputBytes(31))
+	var limitErr *OutputLimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != 31 {
+		t.Fatalf("expected *OutputLimitError with limit 31, got %v", err)
+	}
+	compare(t, tfs.Graph(), `
+.
+├── a1.test
+└── b
+[output truncated at 31 bytes]`[1:])
+
+	if _, err := New(mapfs, ".", MaxOutputBytes(1000)); err != nil {
+		t.Fatalf("expected no error within limit, got %v", err)
+	}
+}
+
+func TestMaxNodes(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a1.test":   {},
+		"b/b1.test": {},
+		"b/b2.test": {},
+	}
+
+	tfs, err := New(mapfs, ".", MaxNodes(3))
+	var limitErr *NodeLimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != 3 {
+		t.Fatalf("expected *NodeLimitError with limit 3, got %v", err)
+	}
+	compare(t, tfs.Graph(), `
+.
+├── a1.test
+└── b
+    ├── b1.test
+[output truncated at 3 entries]`[1:])
+
+	if _, err := New(mapfs, ".", MaxNodes(4)); err != nil {
+		t.Fatalf("expected no error within limit, got %v", err)
+	}
+}
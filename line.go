@@ -0,0 +1,59 @@
+package treefs
+
+import "iter"
+
+// Line is a line of the graph of a TreeFS, kept as its components so that
+// they can be re-styled, colored or aligned without parsing the graph.
+//
+// Components are as rendered, i.e. painted if ColorConnectorsByDepth or
+// ColorNamesByDepth was applied. The Line of a root only has a Name.
+type Line struct {
+	Prefix     string // the pipes and spaces of the ancestors of the entry
+	Connector  string // the connector of the entry, e.g. "├──"
+	Info       string // the bracketed metadata of the entry, e.g. its size
+	Name       string // the name, or path, of the entry
+	Annotation string // any text following Name, with its leading spaces
+	Comment    string // the comment set with Annotations, with its leading spaces
+}
+
+// String returns the Line as it appears in the graph.
+func (l Line) String() string {
+	s := l.Prefix
+	if l.Connector != "" {
+		s += l.Connector + " "
+	}
+	if l.Info != "" {
+		s += l.Info + "  "
+	}
+	return s + l.Name + l.Annotation + l.Comment
+}
+
+// Line returns the i-th Line of the graph of t, in the range [0, Len()).
+func (t TreeFS) Line(i int) Line {
+	return t.tree[i]
+}
+
+// Lines returns the lines of the graph of t, as they appear in Graph, so that
+// they can be post-processed without splitting Graph.
+//
+// It is empty if the OnLine or Stream Opt was applied, since the lines are
+// not kept.
+func (t TreeFS) Lines() []string {
+	lines := make([]string, len(t.tree))
+	for i, line := range t.tree {
+		lines[i] = line.String()
+	}
+	return lines
+}
+
+// AllLines returns an iterator over the lines of the graph of t, like Lines,
+// without building them all up front.
+func (t TreeFS) AllLines() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for _, line := range t.tree {
+			if !yield(line.String()) {
+				return
+			}
+		}
+	}
+}
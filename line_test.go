@@ -0,0 +1,81 @@
+package treefs
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLine(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.test":   {Data: make([]byte, 12)},
+		"a/b/b1.test": {},
+		"a/b/b2.test": {},
+	}
+
+	tfs, err := New(mapfs, "a", Size, Level(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Line{
+		{Name: "a"},
+		{Prefix: "", Connector: teeConnector, Info: "[         12]", Name: "a1.test"},
+		{Prefix: "", Connector: elbowConnector, Info: "[          0]", Name: "b"},
+		{Prefix: spacePrefix, Connector: teeConnector, Info: "[          0]", Name: "b1.test"},
+		{Prefix: spacePrefix, Connector: elbowConnector, Info: "[          0]", Name: "b2.test"},
+	}
+	if tfs.Len() != len(expected) {
+		t.Fatalf("expected %d lines, got %d", len(expected), tfs.Len())
+	}
+	for i, line := range expected {
+		if got := tfs.Line(i); got != line {
+			t.Fatalf("line %d: expected %+v, got %+v", i, line, got)
+		}
+	}
+
+	graph := `
+a
+├── [         12]  a1.test
+└── [          0]  b
+    ├── [          0]  b1.test
+    └── [          0]  b2.test`[1:]
+	compare(t, tfs.Graph(), graph)
+
+	line := Line{Prefix: pipePrefix, Connector: elbowConnector, Name: "c", Annotation: " [broken]"}
+	if got := line.String(); got != "│   └── c [broken]" {
+		t.Fatalf("expected %q, got %q", "│   └── c [broken]", got)
+	}
+}
+
+func TestLines(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.test":   {},
+		"a/b/b1.test": {},
+	}
+
+	tfs, err := New(mapfs, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"a", "├── a1.test", "└── b", "    └── b1.test"}
+	if got := tfs.Lines(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	if got := strings.Join(tfs.Lines(), "\n"); got != tfs.Graph() {
+		t.Errorf("expected lines to join to the graph, got %q", got)
+	}
+
+	var got []string
+	for line := range tfs.AllLines() {
+		got = append(got, line)
+		if len(got) == 2 {
+			break
+		}
+	}
+	if !reflect.DeepEqual(got, expected[:2]) {
+		t.Errorf("expected %q, got %q", expected[:2], got)
+	}
+}
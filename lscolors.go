@@ -0,0 +1,100 @@
+package treefs
+
+import (
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// defaultLSColors is the LS_COLORS value Color uses when the variable is
+// unset, the same defaults as `dircolors`.
+const defaultLSColors = "di=01;34:ln=01;36:pi=40;33:so=01;35:do=01;35:" +
+	"bd=40;33;01:cd=40;33;01:or=40;31;01:ex=01;32"
+
+// lsColors holds the SGR parameters of each file type and extension of an
+// LS_COLORS value.
+type lsColors struct {
+	types map[string]string // by indicator, e.g. "di" for directories
+	exts  []lsExt           // in the order they were given
+}
+
+// lsExt is an extension, or any suffix, of LS_COLORS, e.g. "*.tar=01;31".
+type lsExt struct {
+	suffix string
+	params string
+}
+
+// Color colors the names of entries like `tree -C`, by their type and
+// extension as configured by the LS_COLORS environment variable, or the
+// defaults of `dircolors` if it is unset.
+//
+// Directories, symbolic links, executables, named pipes, sockets and devices
+// are colored by type, and other files by their extension. Color takes
+// precedence over ColorNamesByDepth.
+func Color(t *TreeFS) {
+	env, ok := os.LookupEnv("LS_COLORS")
+	if !ok || env == "" {
+		env = defaultLSColors
+	}
+	t.lsColors = parseLSColors(env)
+}
+
+// Parse the LS_COLORS value s, ignoring malformed entries.
+func parseLSColors(s string) *lsColors {
+	c := &lsColors{types: make(map[string]string)}
+	for _, field := range strings.Split(s, ":") {
+		key, params, ok := strings.Cut(field, "=")
+		if !ok || key == "" || params == "" {
+			continue
+		}
+		if strings.HasPrefix(key, "*") {
+			c.exts = append(c.exts, lsExt{suffix: key[1:], params: params})
+			continue
+		}
+		c.types[key] = params
+	}
+	return c
+}
+
+// Return the SGR parameters of the Node n, or an empty string if it isn't
+// colored.
+func (t *TreeFS) lsColor(n *Node) string {
+	c := t.lsColors
+	mode := n.mode()
+	switch {
+	case n.IsDir:
+		return c.types["di"]
+	case mode&fs.ModeSymlink != 0:
+		if params := c.types["or"]; params != "" && t.broken(n) {
+			return params
+		}
+		return c.types["ln"]
+	case mode&fs.ModeNamedPipe != 0:
+		return c.types["pi"]
+	case mode&fs.ModeSocket != 0:
+		return c.types["so"]
+	case mode&fs.ModeCharDevice != 0:
+		return c.types["cd"]
+	case mode&fs.ModeDevice != 0:
+		return c.types["bd"]
+	}
+
+	if params := c.types["ex"]; params != "" {
+		if fi, _ := n.info(); fi != nil && fi.Mode()&0o111 != 0 {
+			return params
+		}
+	}
+
+	// Like ls, the longest matching suffix wins.
+	var params string
+	longest := -1
+	for _, ext := range c.exts {
+		if len(ext.suffix) > longest && strings.HasSuffix(n.Name, ext.suffix) {
+			params, longest = ext.params, len(ext.suffix)
+		}
+	}
+	if params != "" {
+		return params
+	}
+	return c.types["fi"]
+}
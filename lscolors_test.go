@@ -0,0 +1,44 @@
+package treefs
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestColor(t *testing.T) {
+	t.Setenv("LS_COLORS", "di=01;34:ln=01;36:or=40;31:ex=01;32:*.tar=01;31:*.tar.gz=01;35:bogus")
+
+	mapfs := fstest.MapFS{
+		"a/archive.tar":    {},
+		"a/archive.tar.gz": {},
+		"a/b/b1.test":      {},
+		"a/link":           {Data: []byte("archive.tar"), Mode: fs.ModeSymlink},
+		"a/orphan":         {Data: []byte("missing"), Mode: fs.ModeSymlink},
+		"a/run.sh":         {Mode: 0o755},
+	}
+	got, err := Tree(mapfs, "a", Color)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "a\n" +
+		"├── " + sgr("01;31", "archive.tar") + "\n" +
+		"├── " + sgr("01;35", "archive.tar.gz") + "\n" +
+		"├── " + sgr("01;34", "b") + "\n" +
+		"│   └── b1.test\n" +
+		"├── " + sgr("01;36", "link") + " -> archive.tar\n" +
+		"├── " + sgr("40;31", "orphan") + " -> missing\n" +
+		"└── " + sgr("01;32", "run.sh") + "\n" +
+		"\n" +
+		"1 directory, 4 files, 2 symbolic links"
+	compare(t, got, expected)
+
+	// Without LS_COLORS, the defaults of dircolors are used.
+	t.Setenv("LS_COLORS", "")
+	got, err = Tree(mapfs, "a", Color, Level(1), DirOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, got, "a\n└── "+sgr("01;34", "b")+"\n\n1 directory")
+}
@@ -0,0 +1,56 @@
+package treefs
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+)
+
+// Manifest writes a `sha256sum`-style line with the hash, size and path of
+// every graphed file to w, as the fs.FS is walked:
+//
+//	<sha256>  <size>  <path>
+//
+// which is useful to verify the integrity of embedded assets at build time.
+// Paths are relative to the fs.FS. To only write the manifest, discard the
+// graph with OnLine. Only regular files are hashed, symlinks, named pipes,
+// devices and other irregular files are left out.
+//
+// If writing to w fails, the walk stops and New returns the error. Files
+// that can't be read are left out of the manifest, or reported by Strict.
+func Manifest(w io.Writer) Opt {
+	return func(t *TreeFS) {
+		t.manifest = w
+	}
+}
+
+// Write the manifest line of the file Node n, if the Manifest Opt was
+// applied, returning any error writing it.
+func (t *TreeFS) writeManifest(n *Node) error {
+	// Reading a named pipe or a device could block, and a symlink may point
+	// to a directory.
+	if t.manifest == nil || !n.mode().IsRegular() {
+		return nil
+	}
+
+	f, err := t.fsys.Open(n.Path)
+	if err != nil {
+		if t.strict {
+			t.fail("open", n.Path, err)
+		}
+		return nil
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		if t.strict {
+			t.fail("read", n.Path, err)
+		}
+		return nil
+	}
+
+	_, err = fmt.Fprintf(t.manifest, "%x  %d  %s\n", h.Sum(nil), size, n.Path)
+	return err
+}
@@ -0,0 +1,69 @@
+package treefs
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestManifest(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.test":    {Data: []byte("hello\n")},
+		"a/.a2.test":   {Data: []byte("hidden\n")},
+		"a/b/b1.test":  {},
+		"a/c/c1.test":  {Data: []byte("c1")},
+		"a/d/.d1.test": {},
+	}
+
+	var buf bytes.Buffer
+	if _, err := New(mapfs, "a", Manifest(&buf)); err != nil {
+		t.Fatal(err)
+	}
+	expected := `
+5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03  6  a/a1.test
+e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  0  a/b/b1.test
+d0f631ca1ddba8db3bcfcb9e057cdc98d0379f1bee00e75a545147a27dadd982  2  a/c/c1.test
+`[1:]
+	compare(t, buf.String(), expected)
+
+	// A file root is graphed, and hashed, on its own.
+	buf.Reset()
+	if _, err := New(mapfs, "a/a1.test", Manifest(&buf)); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, buf.String(), "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03  6  a/a1.test\n")
+}
+
+type failWriter struct{}
+
+var errWrite = errors.New("write failed")
+
+func (failWriter) Write([]byte) (int, error) { return 0, errWrite }
+
+func TestManifestWriteError(t *testing.T) {
+	mapfs := fstest.MapFS{"a/a1.test": {}}
+	if _, err := New(mapfs, "a", Manifest(failWriter{})); !errors.Is(err, errWrite) {
+		t.Fatalf("expected %v, got %v", errWrite, err)
+	}
+}
+
+func TestManifestIrregular(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.test":   {Data: []byte("hello\n")},
+		"a/b/b1.test": {},
+		"a/link":      {Data: []byte("b"), Mode: fs.ModeSymlink},
+		"a/pipe":      {Data: []byte("never read"), Mode: fs.ModeNamedPipe},
+	}
+
+	var buf bytes.Buffer
+	if _, err := New(mapfs, "a", Manifest(&buf), Strict); err != nil {
+		t.Fatal(err)
+	}
+	expected := `
+5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03  6  a/a1.test
+e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  0  a/b/b1.test
+`[1:]
+	compare(t, buf.String(), expected)
+}
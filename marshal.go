@@ -0,0 +1,186 @@
+package treefs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+// treeJSON is the JSON representation of a TreeFS, see MarshalJSON.
+type treeJSON struct {
+	Lines       []Line      `json:"lines,omitempty"`
+	Roots       []nodeJSON  `json:"roots"`
+	Meta        string      `json:"meta"`
+	Directories int         `json:"directories"`
+	Files       int         `json:"files"`
+	Irregular   int         `json:"irregular,omitempty"`
+	Symlinks    int         `json:"symlinks,omitempty"`
+	Scanned     int         `json:"scanned,omitempty"`
+	Depth       int         `json:"depth"`
+	Options     optionsJSON `json:"options"`
+}
+
+// nodeJSON is the JSON representation of a Node.
+type nodeJSON struct {
+	Name     string     `json:"name"`
+	Path     string     `json:"path"`
+	IsDir    bool       `json:"isDir,omitempty"`
+	Info     *infoJSON  `json:"info,omitempty"`
+	Target   string     `json:"target,omitempty"`
+	Children []nodeJSON `json:"children,omitempty"`
+}
+
+// infoJSON is the JSON representation of the Info of a Node.
+type infoJSON struct {
+	Size    int64       `json:"size"`
+	Mode    fs.FileMode `json:"mode"`
+	ModTime time.Time   `json:"modTime,omitzero"`
+}
+
+// optionsJSON holds the Opts of a TreeFS that are used once it has been
+// walked, e.g. by its String, JSON and XML methods.
+type optionsJSON struct {
+	DirOnly       bool   `json:"dirOnly,omitempty"`
+	NoReport      bool   `json:"noReport,omitempty"`
+	DeepestReport int    `json:"deepestReport,omitempty"`
+	LargestReport int    `json:"largestReport,omitempty"`
+	Sizes         bool   `json:"sizes,omitempty"`
+	MTime         bool   `json:"mtime,omitempty"`
+	RelativeTime  bool   `json:"relativeTime,omitempty"`
+	TimeFormat    string `json:"timeFormat,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface for TreeFS.
+//
+// It encodes the graph, the walked Nodes with their Info, the metadata and
+// the Opts needed to render them again, so that a TreeFS can be persisted
+// and later reloaded with UnmarshalJSON, e.g. for diffing, without access to
+// the original fs.FS.
+func (t TreeFS) MarshalJSON() ([]byte, error) {
+	v := treeJSON{
+		Lines:       t.tree,
+		Roots:       make([]nodeJSON, 0, len(t.roots)),
+		Meta:        t.Meta(),
+		Directories: t.NDirs,
+		Files:       t.NFiles,
+		Irregular:   t.NIrregular,
+		Symlinks:    t.NSymlinks,
+		Scanned:     t.NScanned,
+		Depth:       t.maxDepth,
+		Options: optionsJSON{
+			DirOnly:       t.dirOnly,
+			NoReport:      t.noReport,
+			DeepestReport: t.deepestN,
+			LargestReport: t.largestN,
+			Sizes:         t.sizeFmt != nil,
+			MTime:         t.mtime,
+			RelativeTime:  t.relTime,
+			TimeFormat:    t.timeLayout,
+		},
+	}
+	for _, root := range t.roots {
+		v.Roots = append(v.Roots, marshalNode(root))
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for TreeFS.
+//
+// It replaces t with the TreeFS encoded by MarshalJSON. The Info of the
+// Nodes of the result only has the size, mode and modification time of their
+// entry, and its metadata is stringified as it was when encoded. The result
+// has no fs.FS, so Opts reading entries, such as Annotations, are not
+// applied again.
+func (t *TreeFS) UnmarshalJSON(data []byte) error {
+	var v treeJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("treefs: decoding TreeFS JSON: %w", err)
+	}
+
+	tfs := defaults()
+	tfs.tree = v.Lines
+	for _, root := range v.Roots {
+		n, err := root.node(0)
+		if err != nil {
+			return fmt.Errorf("treefs: decoding TreeFS JSON: %w", err)
+		}
+		tfs.roots = append(tfs.roots, n)
+	}
+	meta := v.Meta
+	tfs.metaFormat = func(int, int) string { return meta }
+	tfs.NDirs = v.Directories
+	tfs.NFiles = v.Files
+	tfs.NIrregular = v.Irregular
+	tfs.NSymlinks = v.Symlinks
+	tfs.NScanned = v.Scanned
+	tfs.maxDepth = v.Depth
+
+	tfs.dirOnly = v.Options.DirOnly
+	tfs.noReport = v.Options.NoReport
+	tfs.deepestN = v.Options.DeepestReport
+	tfs.largestN = v.Options.LargestReport
+	if v.Options.Sizes {
+		// The sizes of the graph and metadata are already formatted.
+		tfs.sizeFmt = rawSize
+	}
+	tfs.mtime = v.Options.MTime
+	tfs.relTime = v.Options.RelativeTime
+	tfs.timeLayout = v.Options.TimeFormat
+
+	*t = tfs
+	return nil
+}
+
+// Return the JSON representation of the Node n and the Nodes below it.
+func marshalNode(n *Node) nodeJSON {
+	v := nodeJSON{
+		Name:   n.Name,
+		Path:   n.Path,
+		IsDir:  n.IsDir,
+		Target: n.Target,
+	}
+	if fi, _ := n.info(); fi != nil {
+		v.Info = &infoJSON{Size: fi.Size(), Mode: fi.Mode(), ModTime: fi.ModTime()}
+	}
+	for _, child := range n.Children {
+		v.Children = append(v.Children, marshalNode(child))
+	}
+	return v
+}
+
+// Convert v, at depth depth, to a Node, counting the entries below it.
+//
+// Since the Nodes may not come from MarshalJSON, their paths are validated,
+// and the names of the entries below the root must be single path elements,
+// as those of walked entries are.
+func (v nodeJSON) node(depth int) (*Node, error) {
+	if !fs.ValidPath(v.Path) || depth > 0 && !validName(v.Name) {
+		return nil, &fs.PathError{Op: "decode", Path: v.Path, Err: ErrInvalidName}
+	}
+
+	n := &Node{
+		Name:   v.Name,
+		Path:   v.Path,
+		IsDir:  v.IsDir,
+		Target: v.Target,
+		depth:  depth,
+	}
+	if v.Info != nil {
+		n.Info = nodeInfo{name: v.Name, size: v.Info.Size, mode: v.Info.Mode, modTime: v.Info.ModTime}
+	}
+	for _, child := range v.Children {
+		c, err := child.node(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if c.IsDir {
+			n.ndirs += 1 + c.ndirs
+			n.nfiles += c.nfiles
+		} else {
+			n.nfiles++
+		}
+		n.Children = append(n.Children, c)
+	}
+	return n, nil
+}
@@ -0,0 +1,92 @@
+package treefs
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	modTime := time.Date(2022, time.March, 4, 5, 6, 0, 0, time.UTC)
+	mapfs := fstest.MapFS{
+		"a/a1.test":     {Data: []byte("a1"), ModTime: modTime},
+		"a/b/b1.test":   {Data: []byte("b1b1"), ModTime: modTime},
+		"a/b/c/c1.test": {Data: []byte("c"), ModTime: modTime},
+		"a/d/.hidden":   {},
+		"a/l":           {Mode: fs.ModeSymlink, Data: []byte("a1.test")},
+	}
+
+	opts := []Opt{HumanSize, DeepestReport(2), LargestReport(2), MetaFormatter(func(ndirs, nfiles int) string {
+		return "custom metadata"
+	})}
+	for _, opts := range [][]Opt{nil, {DirOnly}, opts} {
+		tfs, err := New(mapfs, "a", opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := json.Marshal(tfs)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got TreeFS
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		compare(t, got.String(), tfs.String())
+		compare(t, got.JSON(), tfs.JSON())
+		compare(t, got.XML(), tfs.XML())
+		if !reflect.DeepEqual(got.Stats(), tfs.Stats()) {
+			t.Errorf("expected %+v, got %+v", tfs.Stats(), got.Stats())
+		}
+		if !reflect.DeepEqual(got.Largest(3), tfs.Largest(3)) {
+			t.Errorf("expected %v, got %v", tfs.Largest(3), got.Largest(3))
+		}
+		if got.NumEntries() != tfs.NumEntries() {
+			t.Errorf("expected %d entries, got %d", tfs.NumEntries(), got.NumEntries())
+		}
+		if got.Depth() != tfs.Depth() || got.NScanned != tfs.NScanned {
+			t.Errorf("expected depth %d and %d scanned, got %d and %d", tfs.Depth(), tfs.NScanned, got.Depth(), got.NScanned)
+		}
+
+		for p, n := range tfs.All() {
+			if p == "a" {
+				continue
+			}
+			var gn *Node
+			for gp, m := range got.All() {
+				if gp == p {
+					gn = m
+				}
+			}
+			if gn == nil {
+				t.Fatalf("expected %s to be reloaded", p)
+			}
+			if gn.Name != n.Name || gn.IsDir != n.IsDir || gn.Info.Size() != n.Info.Size() || !gn.Info.ModTime().Equal(n.Info.ModTime()) {
+				t.Errorf("expected %s to be %+v, got %+v", p, n, gn)
+			}
+		}
+	}
+
+	var got TreeFS
+	if err := json.Unmarshal([]byte(`{"roots":{}}`), &got); err == nil {
+		t.Error("expected an error decoding invalid JSON")
+	}
+
+	for _, data := range []string{
+		`{"roots":[{"name":"a","path":"/a"}]}`,
+		`{"roots":[{"name":"a","path":"a","children":[{"name":"../../escaped","path":"a/b"}]}]}`,
+		`{"roots":[{"name":"a","path":"a","children":[{"name":"..","path":"a/b"}]}]}`,
+		`{"roots":[{"name":"a","path":"a","children":[{"name":".","path":"a/b"}]}]}`,
+		`{"roots":[{"name":"a","path":"a","children":[{"name":"b\\c","path":"a/b"}]}]}`,
+		`{"roots":[{"name":"a","path":"a","children":[{"name":"b","path":"a/../../b"}]}]}`,
+	} {
+		if err := json.Unmarshal([]byte(data), &got); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("%s: expected %v, got %v", data, ErrInvalidName, err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package treefs
+
+import (
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// sniffLen is the number of bytes http.DetectContentType considers.
+const sniffLen = 512
+
+// MIME displays the MIME type of each file, e.g. "[image/png]", which is
+// useful when auditing embedded asset bundles.
+//
+// The type is guessed from the file's extension or, failing that, sniffed
+// from its first 512 bytes with http.DetectContentType. Directories and
+// symbolic links are displayed as "inode/directory" and "inode/symlink".
+func MIME(t *TreeFS) {
+	t.mimeTypes = true
+}
+
+// Return the MIME type of the Node n, without any parameters, or "?" if it
+// is unknown.
+func (t *TreeFS) mimeType(n *Node) string {
+	switch mode := n.mode(); {
+	case mode&fs.ModeSymlink != 0:
+		return "inode/symlink"
+	case n.IsDir:
+		return "inode/directory"
+	case !mode.IsRegular():
+		return "?"
+	}
+
+	if typ := mime.TypeByExtension(path.Ext(n.Name)); typ != "" {
+		return mediaType(typ)
+	}
+
+	f, err := t.fsys.Open(n.Path)
+	if err != nil {
+		if t.strict {
+			t.fail("open", n.Path, err)
+		}
+		return "?"
+	}
+	defer f.Close()
+
+	buf := make([]byte, sniffLen)
+	read, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		if t.strict {
+			t.fail("read", n.Path, err)
+		}
+		return "?"
+	}
+	return mediaType(http.DetectContentType(buf[:read]))
+}
+
+// Return the MIME type typ without its parameters, e.g. "; charset=utf-8".
+func mediaType(typ string) string {
+	typ, _, _ = strings.Cut(typ, ";")
+	return strings.TrimSpace(typ)
+}
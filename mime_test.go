@@ -0,0 +1,28 @@
+package treefs
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMIME(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/b/b1":       {Data: []byte("\x89PNG\r\n\x1a\n")},
+		"a/index.html": {Data: []byte("not really html")},
+		"a/link":       {Data: []byte("index.html"), Mode: fs.ModeSymlink},
+		"a/notes":      {Data: []byte("plain text")},
+	}
+
+	tfs, err := New(mapfs, "a", MIME)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, tfs.Graph(), `
+a
+├── [inode/directory]  b
+│   └── [image/png]  b1
+├── [text/html]  index.html
+├── [inode/symlink]  link -> index.html
+└── [text/plain]  notes`[1:])
+}
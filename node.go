@@ -0,0 +1,124 @@
+package treefs
+
+import (
+	"io/fs"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Node is a single entry of a tree, together with its children if it is a
+// directory.
+//
+// The Nodes walked into a TreeFS are returned by its Root and Roots methods,
+// so that callers can build their own renderers and analyses on top of a
+// single walk. They must not be modified.
+type Node struct {
+	Name     string      // the base name of the entry
+	Path     string      // the slash-separated path of the entry
+	IsDir    bool        // whether the entry is a directory
+	Info     fs.FileInfo // the entry's metadata, nil if unknown
+	Target   string      // the target of a symbolic link, if known
+	Children []*Node     // the entries of a directory
+
+	entry    fs.DirEntry // the entry the Node was walked from, if any
+	depth    int         // the depth of the Node, 0 being the root
+	ndirs    int         // the number of directories below the Node
+	nfiles   int         // the number of files below the Node
+	sig      string      // memoized result of signature
+	du       int64       // accumulated size of a directory, see DiskUsage
+	real     string      // the resolved path of entries below followed symlinks
+	matched  bool        // whether the Node is within a directory matching Match
+	ignore   []string    // the IgnoreFile patterns of the Node's directories
+	deferred bool        // whether the directory is walked once streamed
+
+	infoOnce sync.Once // guards the loading of Info from entry
+	infoErr  error     // the error of the entry's Info method, if it failed
+}
+
+// nodeInfo is an fs.FileInfo for Nodes whose metadata did not come from an
+// fs.FS, e.g. Nodes imported from `tree -J`.
+type nodeInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i nodeInfo) Name() string       { return i.name }
+func (i nodeInfo) Size() int64        { return i.size }
+func (i nodeInfo) Mode() fs.FileMode  { return i.mode }
+func (i nodeInfo) ModTime() time.Time { return i.modTime }
+func (i nodeInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i nodeInfo) Sys() any           { return nil }
+
+// Stat returns the Info of n, loading it from the entry n was walked from the
+// first time if it isn't already, e.g. for the Nodes visited by Walk. It is
+// safe for concurrent use.
+func (n *Node) Stat() (fs.FileInfo, error) {
+	return n.info()
+}
+
+// Return the metadata of n, calling DirEntry.Info at most once, since it can
+// be expensive on remote filesystems and is needed by several Opts.
+//
+// It is safe for concurrent use, since the accessors of a TreeFS load the
+// Info of its Nodes after New returns.
+func (n *Node) info() (fs.FileInfo, error) {
+	n.infoOnce.Do(func() {
+		if n.Info != nil || n.entry == nil {
+			return
+		}
+		n.Info, n.infoErr = n.entry.Info()
+		if n.infoErr != nil {
+			// Don't hold on to any non-nil, but invalid, fs.FileInfo.
+			n.Info = nil
+		}
+	})
+	return n.Info, n.infoErr
+}
+
+// Return the type bits of the mode of n.
+func (n *Node) mode() fs.FileMode {
+	switch {
+	case n.entry != nil:
+		return n.entry.Type()
+	case n.Info != nil:
+		return n.Info.Mode().Type()
+	case n.IsDir:
+		return fs.ModeDir
+	}
+	return 0
+}
+
+// Return the path of n with any followed symbolic links resolved.
+func (n *Node) realPath() string {
+	if n.real != "" {
+		return n.real
+	}
+	return n.Path
+}
+
+// Return the path of n relative to the root it was walked from, or "." for
+// the root itself.
+func (n *Node) relPath() string {
+	if n.depth == 0 {
+		return "."
+	}
+	i := len(n.Path)
+	for range n.depth {
+		if i = strings.LastIndexByte(n.Path[:i], '/'); i < 0 {
+			return n.Path
+		}
+	}
+	return n.Path[i+1:]
+}
+
+// Load the Info of the entries below n, ignoring failures, which leave it
+// nil.
+func (n *Node) load() {
+	for _, child := range n.Children {
+		_, _ = child.info()
+		child.load()
+	}
+}
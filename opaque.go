@@ -0,0 +1,88 @@
+package treefs
+
+import "io/fs"
+
+// DefaultOpaque is the list of directory names Opaque uses when given none:
+// dependency, build output and version control directories, whose contents
+// are rarely of interest in the graph of a project.
+var DefaultOpaque = []string{
+	".git", ".hg", ".svn",
+	"node_modules", "bower_components",
+	"vendor", "target", "dist", "build",
+	"__pycache__", ".venv", ".tox",
+}
+
+// Opaque lists the directories with any of the names names, but never
+// descends into them, displaying the number of their entries instead:
+//
+//	├── node_modules [1204 entries]
+//
+// If no names are given, DefaultOpaque is used. The entries of opaque
+// directories are not counted in the metadata.
+func Opaque(names ...string) Opt {
+	if len(names) == 0 {
+		names = DefaultOpaque
+	}
+	return func(t *TreeFS) {
+		if t.opaque == nil {
+			t.opaque = make(map[string]bool)
+		}
+		for _, name := range names {
+			t.opaque[name] = true
+		}
+	}
+}
+
+// Collapse walks the directories with any of the names names, but displays
+// them on a single line with their total number of entries, like Opaque:
+//
+//	├── node_modules [48213 entries]
+//
+// Unlike with Opaque, their entries are counted in the metadata, at the cost
+// of walking them. If no names are given, DefaultOpaque is used.
+func Collapse(names ...string) Opt {
+	if len(names) == 0 {
+		names = DefaultOpaque
+	}
+	return func(t *TreeFS) {
+		if t.collapsed == nil {
+			t.collapsed = make(map[string]bool)
+		}
+		for _, name := range names {
+			t.collapsed[name] = true
+		}
+	}
+}
+
+// Record the total number of entries of the walked directory Node n, whose
+// entries are then not rendered.
+func (t *TreeFS) collapse(n *Node) {
+	if t.summaries == nil {
+		t.summaries = make(map[*Node]int)
+	}
+	t.summaries[n] = n.ndirs + n.nfiles
+}
+
+// Count the entries of the opaque directory Node n, as they would be
+// displayed, without walking it.
+func (t *TreeFS) summarize(n *Node) {
+	entries, err := fs.ReadDir(t.fsys, n.Path)
+	if err != nil {
+		if t.strict {
+			t.fail("readdir", n.Path, err)
+		}
+		return
+	}
+
+	t.NScanned += len(entries)
+	count := 0
+	for _, entry := range entries {
+		if t.allow(entry) {
+			count++
+		}
+	}
+	if t.summaries == nil {
+		t.summaries = make(map[*Node]int)
+	}
+	t.summaries[n] = count
+}
@@ -0,0 +1,63 @@
+package treefs
+
+import (
+	"fmt"
+	"io/fs"
+	"os/user"
+	"strconv"
+)
+
+// ShowOwner displays the name of the user owning each entry, like `tree -u`,
+// when FileInfo.Sys() exposes it, as on Unix with os.DirFS. The numeric ID is
+// displayed if the user can't be looked up.
+func ShowOwner(t *TreeFS) {
+	t.owner = true
+}
+
+// ShowGroup displays the name of the group owning each entry, like `tree
+// -g`, in the same way ShowOwner displays its user.
+func ShowGroup(t *TreeFS) {
+	t.group = true
+}
+
+// Format the user owning fi, or a placeholder if it is unknown.
+func (t *TreeFS) formatOwner(fi fs.FileInfo) string {
+	uid, _, ok := sysOwner(fi)
+	if !ok {
+		return fmt.Sprintf("%-8s", "?")
+	}
+	if t.userNames == nil {
+		t.userNames = make(map[uint32]string)
+	}
+	name, ok := t.userNames[uid]
+	if !ok {
+		id := strconv.FormatUint(uint64(uid), 10)
+		name = id
+		if u, err := user.LookupId(id); err == nil {
+			name = u.Username
+		}
+		t.userNames[uid] = name
+	}
+	return fmt.Sprintf("%-8s", name)
+}
+
+// Format the group owning fi, or a placeholder if it is unknown.
+func (t *TreeFS) formatGroup(fi fs.FileInfo) string {
+	_, gid, ok := sysOwner(fi)
+	if !ok {
+		return fmt.Sprintf("%-8s", "?")
+	}
+	if t.groupNames == nil {
+		t.groupNames = make(map[uint32]string)
+	}
+	name, ok := t.groupNames[gid]
+	if !ok {
+		id := strconv.FormatUint(uint64(gid), 10)
+		name = id
+		if g, err := user.LookupGroupId(id); err == nil {
+			name = g.Name
+		}
+		t.groupNames[gid] = name
+	}
+	return fmt.Sprintf("%-8s", name)
+}
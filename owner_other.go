@@ -0,0 +1,10 @@
+//go:build !unix
+
+package treefs
+
+import "io/fs"
+
+// Report the owner of fi as unknown on platforms without a syscall.Stat_t.
+func sysOwner(fi fs.FileInfo) (uid, gid uint32, ok bool) {
+	return 0, 0, false
+}
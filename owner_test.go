@@ -0,0 +1,39 @@
+//go:build unix
+
+package treefs
+
+import (
+	"fmt"
+	"os/user"
+	"syscall"
+	"testing"
+	"testing/fstest"
+)
+
+func TestOwner(t *testing.T) {
+	root, err := user.LookupId("0")
+	if err != nil {
+		t.Skip("no user with ID 0:", err)
+	}
+	group, err := user.LookupGroupId("0")
+	if err != nil {
+		t.Skip("no group with ID 0:", err)
+	}
+
+	mapfs := fstest.MapFS{
+		"a1.test": {Sys: &syscall.Stat_t{Uid: 0, Gid: 0}},
+		"a2.test": {Sys: &syscall.Stat_t{Uid: 4242424, Gid: 4242424}},
+		"a3.test": {},
+	}
+	got, err := Graph(mapfs, ".", ShowOwner, ShowGroup, Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := fmt.Sprintf(`
+.
+├── [%-8s %-8s           0]  a1.test
+├── [4242424  4242424            0]  a2.test
+└── [?        ?                  0]  a3.test`[1:], root.Username, group.Name)
+	compare(t, got, expected)
+}
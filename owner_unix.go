@@ -0,0 +1,21 @@
+//go:build unix
+
+package treefs
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// Return the user and group IDs of the owner of fi, from the syscall.Stat_t
+// returned by Sys(), and whether they are known.
+func sysOwner(fi fs.FileInfo) (uid, gid uint32, ok bool) {
+	if fi == nil {
+		return 0, 0, false
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return st.Uid, st.Gid, true
+}
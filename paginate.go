@@ -0,0 +1,83 @@
+package treefs
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Paginate displays only the first n entries of directories with more than
+// n entries, followed by a line with the number of entries left out:
+//
+//	├── 0001.json
+//	├── 0002.json
+//	└── … and 3,482 more
+//
+// which is a middle ground between the full graph and leaving out large
+// directories altogether. Entries left out are still counted in the
+// metadata.
+func Paginate(n int) Opt {
+	return func(t *TreeFS) {
+		// Ignore if n <= 0.
+		if n <= 0 {
+			return
+		}
+		t.pageSize, t.moreEntries = n, false
+	}
+}
+
+// MaxEntriesPerDir displays only the first n entries of directories with
+// more than n entries, like Paginate, but counts the entries left out as
+// such, as other tree-like tools do:
+//
+//	├── 0001.json
+//	├── 0002.json
+//	└── … 950 more entries
+//
+// Unlike FileLimit, directories with more than n entries are still walked,
+// and their first n entries displayed.
+func MaxEntriesPerDir(n int) Opt {
+	return func(t *TreeFS) {
+		// Ignore if n <= 0.
+		if n <= 0 {
+			return
+		}
+		t.pageSize = n
+		t.moreEntries = true
+	}
+}
+
+// Render the line of the entries of a directory left out by Paginate, with
+// each line preceded by prefix.
+func (t *TreeFS) more(prefix string, depth, n int) {
+	name := fmt.Sprintf("… and %s more", thousands(n))
+	if t.moreEntries {
+		noun := "entries"
+		if n == 1 {
+			noun = "entry"
+		}
+		name = fmt.Sprintf("… %s more %s", thousands(n), noun)
+	}
+	t.emit(Line{
+		Prefix:    prefix,
+		Connector: t.paintConnector(t.style.Elbow, depth),
+		Name:      name,
+	})
+}
+
+// Format n with commas separating groups of thousands.
+func thousands(n int) string {
+	return thousands64(int64(n))
+}
+
+// Format n with commas separating groups of thousands.
+func thousands64(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign, s = "-", s[1:]
+	}
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return sign + s
+}
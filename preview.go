@@ -0,0 +1,78 @@
+package treefs
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf8"
+)
+
+// defaultPreviewBytes is the number of bytes Preview reads when given a
+// non-positive n.
+const defaultPreviewBytes = 80
+
+// Preview appends a dimmed preview of the first line of each text file after
+// its name, which is useful for exploring config or test data directories.
+//
+// At most the first n bytes of each file are read, which also bounds the
+// length of a preview. Files whose first n bytes don't look like text, and
+// files with an empty first line, are not previewed.
+func Preview(n int) Opt {
+	if n <= 0 {
+		n = defaultPreviewBytes
+	}
+	return func(t *TreeFS) {
+		t.previewBytes = n
+	}
+}
+
+// Return the dimmed preview of the file Node n, or an empty string if it
+// should not be previewed.
+func (t *TreeFS) preview(n *Node) string {
+	if t.previewBytes == 0 || n.IsDir || n.entry == nil || !n.entry.Type().IsRegular() {
+		return ""
+	}
+
+	f, err := t.fsys.Open(n.Path)
+	if err != nil {
+		if t.strict {
+			t.fail("open", n.Path, err)
+		}
+		return ""
+	}
+	defer f.Close()
+
+	buf := make([]byte, t.previewBytes)
+	read, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		if t.strict {
+			t.fail("read", n.Path, err)
+		}
+		return ""
+	}
+
+	line := firstLine(buf[:read])
+	if line == "" {
+		return ""
+	}
+	if t.noColor {
+		return line
+	}
+	return sgr("2", line)
+}
+
+// Return the first line of data, or an empty string if data is not text.
+func firstLine(data []byte) string {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		data = data[:i]
+	}
+	data = bytes.TrimRight(data, "\r")
+
+	// A prefix may cut a multi-byte rune short, which is still text.
+	for i := 0; i < utf8.UTFMax && len(data) > 0 && !utf8.Valid(data); i++ {
+		data = data[:len(data)-1]
+	}
+	if !utf8.Valid(data) || bytes.ContainsAny(data, "\x00\x1b") {
+		return ""
+	}
+	return string(data)
+}
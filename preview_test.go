@@ -0,0 +1,30 @@
+package treefs
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestPreview(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a.yaml":   {Data: []byte("name: treefs\nversion: 1\n")},
+		"b.bin":    {Data: []byte{0xff, 0x00, 0x01}},
+		"c.txt":    {Data: []byte("a very long first line")},
+		"d/e.conf": {Data: []byte("\nsecond line")},
+	}
+
+	got, err := Graph(mapfs, ".", Preview(12))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dim := func(s string) string {
+		return "\x1b[2m" + s + "\x1b[0m"
+	}
+	expected := ".\n" +
+		"├── a.yaml  " + dim("name: treefs") + "\n" +
+		"├── b.bin\n" +
+		"├── c.txt  " + dim("a very long ") + "\n" +
+		"└── d\n" +
+		"    └── e.conf"
+	compare(t, got, expected)
+}
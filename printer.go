@@ -0,0 +1,45 @@
+package treefs
+
+import (
+	"io"
+	"io/fs"
+)
+
+// Printer renders the graphs of many fs.FS values using the same Opts.
+//
+// The Opts given to NewPrinter are applied once, and the buffers used while
+// walking are reused between calls to Print, so services rendering many
+// filesystems don't pay the setup cost on every call.
+//
+// A Printer is not safe for concurrent use.
+type Printer struct {
+	tmpl  TreeFS // TreeFS with the Opts applied, copied for each Print
+	lines []Line // graph buffer reused between calls to Print
+}
+
+// NewPrinter returns a Printer that renders with the Opts opts.
+func NewPrinter(opts ...Opt) *Printer {
+	p := &Printer{tmpl: defaults()}
+	for _, opt := range opts {
+		opt(&p.tmpl)
+	}
+	return p
+}
+
+// Print writes the graph and metadata of the fs.FS fsys with name name to w,
+// followed by a newline.
+func (p *Printer) Print(fsys fs.FS, name string, w io.Writer) error {
+	tfs := p.tmpl
+	tfs.tree = p.lines[:0]
+	if notTerminal(w) {
+		tfs.noColor = true
+	}
+	if err := build(&tfs, fsys, name); err != nil {
+		return err
+	}
+	// Hold on to the (possibly grown) buffer for the next call.
+	p.lines = tfs.tree[:0]
+
+	_, err := tfs.WriteTo(w)
+	return err
+}
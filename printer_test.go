@@ -0,0 +1,35 @@
+package treefs
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestPrinter(t *testing.T) {
+	fsyss := []fstest.MapFS{
+		{
+			"a1.test":   {},
+			"b/b1.test": {},
+			"b/b2.test": {},
+		},
+		{
+			"c/c1.test":   {},
+			"c/d/d1.test": {},
+		},
+	}
+
+	p := NewPrinter(Level(2))
+	for _, fsys := range fsyss {
+		var b strings.Builder
+		if err := p.Print(fsys, ".", &b); err != nil {
+			t.Fatal(err)
+		}
+
+		expected, err := Tree(fsys, ".", Level(2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		compare(t, b.String(), expected+"\n")
+	}
+}
@@ -0,0 +1,41 @@
+package treefs
+
+// Profiles bundle coherent sets of Opts behind a single Opt. Opts given
+// after a profile override it, e.g. New(fsys, ".", Detailed, Level(2)).
+
+// Minimal graphs the shape of a project and little else: opaque directories
+// (see Opaque) aren't walked, identical siblings are folded and directories
+// with more than 20 entries are paginated.
+func Minimal(t *TreeFS) {
+	apply(t, Opaque(), FoldIdentical, Paginate(20))
+}
+
+// Detailed graphs everything there is to see, with colors: hidden entries,
+// human readable sizes, modification times and broken symbolic links.
+func Detailed(t *TreeFS) {
+	apply(t,
+		Hidden,
+		HumanSize,
+		ShowMTime,
+		BrokenLinks,
+		ColorConnectorsByDepth(),
+		ColorNamesByDepth(),
+	)
+}
+
+// CI graphs deterministic, uncolored output suited to build logs and golden
+// files: connectors are drawn with ASCIIStyle, colors set by any Opt are
+// turned off, hidden entries and exact sizes are shown, opaque directories
+// aren't walked, and any entry that can't be read fails the build (see
+// Strict).
+func CI(t *TreeFS) {
+	apply(t, WithStyle(ASCIIStyle), Hidden, Size, Opaque(), Strict)
+	t.noColor = true
+}
+
+// Apply the Opts opts to t, in order.
+func apply(t *TreeFS, opts ...Opt) {
+	for _, opt := range opts {
+		opt(t)
+	}
+}
@@ -0,0 +1,71 @@
+package treefs
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestProfiles(t *testing.T) {
+	mapfs := fstest.MapFS{
+		".env":                 {Data: make([]byte, 3)},
+		"main.go":              {Data: make([]byte, 2048), ModTime: time.Date(2022, time.June, 1, 10, 0, 0, 0, time.Local)},
+		"node_modules/a/a.js":  {},
+		"locales/de/a.json":    {},
+		"locales/en/a.json":    {},
+		"locales/de/b/c.json":  {},
+		"locales/en/b/c.json":  {},
+		"node_modules/b/b.js":  {},
+		"node_modules/.bin/sh": {},
+	}
+
+	for _, tc := range []struct {
+		tcname   string
+		opts     []Opt
+		expected string
+	}{
+		{
+			tcname: "Minimal",
+			opts:   []Opt{Minimal},
+			expected: `
+.
+├── locales
+│   ├── de
+│   │   ├── a.json
+│   │   └── b
+│   │       └── c.json
+│   └── en [same structure as de]
+├── main.go
+└── node_modules [2 entries]
+
+6 directories, 5 files`[1:],
+		},
+		{
+			tcname: "CI",
+			opts:   []Opt{ColorNamesByDepth(), CI, Level(1)},
+			expected: ".\n" +
+				"|-- [          3]  .env\n" +
+				"|-- [          0]  locales\n" +
+				"|-- [       2048]  main.go\n" +
+				"`-- [          0]  node_modules [3 entries]\n" +
+				"\n" +
+				"2 directories, 2 files",
+		},
+	} {
+		t.Run(tc.tcname, func(t *testing.T) {
+			got, err := Tree(mapfs, ".", tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			compare(t, got, tc.expected)
+		})
+	}
+
+	tfs, err := New(mapfs, ".", at(now), Detailed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tfs.hidden || tfs.sizeFmt == nil || !tfs.mtime || !tfs.brokenLinks || tfs.namePalette == nil {
+		t.Fatal("expected Detailed to show hidden entries, sizes, times, broken links and colors")
+	}
+}
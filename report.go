@@ -0,0 +1,179 @@
+package treefs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MetaFormatter formats the metadata returned by Meta, and displayed by
+// String, with format, so that it can be localized or rephrased:
+//
+//	treefs.MetaFormatter(func(ndirs, nfiles int) string {
+//		return fmt.Sprintf("%d Ordner, %d Dateien", ndirs, nfiles)
+//	})
+//
+// format is called with the number of directories and files. Its result is
+// used as is, without the disk usage of DiskUsage or the number of irregular
+// files.
+func MetaFormatter(format func(ndirs, nfiles int) string) Opt {
+	return func(t *TreeFS) {
+		t.metaFormat = format
+	}
+}
+
+// DirCounts appends the number of files and directories below each
+// directory, at any depth, after its name, so that large trees are
+// summarized at every level:
+//
+//	├── internal (12 files, 3 dirs)
+//
+// Directories displayed with a number of entries instead, e.g. by Opaque,
+// are left as is.
+func DirCounts(t *TreeFS) {
+	t.dirCounts = true
+}
+
+// Return the counts of the directory Node n displayed by DirCounts, with a
+// leading space, or an empty string if there are none.
+func (t *TreeFS) dirCount(n *Node) string {
+	if !n.IsDir {
+		return ""
+	}
+	if _, ok := t.summaries[n]; ok {
+		return ""
+	}
+	if _, ok := t.overLimit[n]; ok {
+		return ""
+	}
+	return " (" + plural(n.nfiles, "file", "files") + ", " + plural(n.ndirs, "dir", "dirs") + ")"
+}
+
+// PathDepth is a path together with its depth below the root of its tree.
+type PathDepth struct {
+	Path  string
+	Depth int
+}
+
+// Deepest returns up to n of the deepest paths found, deepest first.
+//
+// Only leaves, i.e. files and directories without displayed entries, are
+// considered, since the ancestors of a deep path are never deeper than it.
+// Paths of equal depth are ordered as they are displayed.
+func (t TreeFS) Deepest(n int) []PathDepth {
+	if n <= 0 {
+		return nil
+	}
+
+	var leaves []PathDepth
+	for _, root := range t.roots {
+		root.leaves(&leaves)
+	}
+
+	sort.SliceStable(leaves, func(i, j int) bool {
+		return leaves[i].Depth > leaves[j].Depth
+	})
+	if n < len(leaves) {
+		leaves = leaves[:n]
+	}
+	return leaves
+}
+
+// DeepestReport appends a report of the n deepest paths found, and their
+// depths, after the metadata of the TreeFS's String method.
+//
+// It helps detect pathological nesting in generated or vendored trees.
+func DeepestReport(n int) Opt {
+	return func(t *TreeFS) {
+		// Ignore if n <= 0.
+		if n <= 0 {
+			return
+		}
+		t.deepestN = n
+	}
+}
+
+// Return the report appended by DeepestReport.
+func (t TreeFS) deepestReport() string {
+	var b strings.Builder
+	b.WriteString("deepest paths:")
+	for _, pd := range t.Deepest(t.deepestN) {
+		fmt.Fprintf(&b, "\n%4d  %s", pd.Depth, pd.Path)
+	}
+	return b.String()
+}
+
+// Append the leaves below n, in display order, to leaves.
+func (n *Node) leaves(leaves *[]PathDepth) {
+	for _, child := range n.Children {
+		if len(child.Children) == 0 {
+			*leaves = append(*leaves, PathDepth{Path: child.Path, Depth: child.depth})
+			continue
+		}
+		child.leaves(leaves)
+	}
+}
+
+// PathSize is the path of a file together with its size in bytes.
+type PathSize struct {
+	Path string
+	Size int64
+}
+
+// Largest returns up to n of the largest files found, largest first, e.g. to
+// find out why an embed.FS is bigger than expected.
+//
+// Only files whose size is known are considered. Files of equal size are
+// ordered as they are displayed.
+func (t TreeFS) Largest(n int) []PathSize {
+	if n <= 0 {
+		return nil
+	}
+
+	var files []PathSize
+	var visit func(n *Node)
+	visit = func(n *Node) {
+		for _, child := range n.Children {
+			if child.IsDir {
+				visit(child)
+				continue
+			}
+			if fi, err := child.info(); err == nil && fi != nil && fi.Mode().IsRegular() {
+				files = append(files, PathSize{Path: child.Path, Size: fi.Size()})
+			}
+		}
+	}
+	for _, root := range t.roots {
+		visit(root)
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Size > files[j].Size
+	})
+	if n < len(files) {
+		files = files[:n]
+	}
+	return files
+}
+
+// LargestReport appends a report of the n largest files found, and their
+// sizes, after the metadata of the TreeFS's String method.
+func LargestReport(n int) Opt {
+	return func(t *TreeFS) {
+		// Ignore if n <= 0.
+		if n <= 0 {
+			return
+		}
+		t.largestN = n
+	}
+}
+
+// Return the report appended by LargestReport.
+func (t TreeFS) largestReport() string {
+	var b strings.Builder
+	b.WriteString("largest files:")
+	for _, ps := range t.Largest(t.largestN) {
+		fmt.Fprintf(&b, "\n%s  %s", humanSize(ps.Size), ps.Path)
+	}
+	return b.String()
+}
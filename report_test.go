@@ -0,0 +1,97 @@
+package treefs
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDeepest(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a1.test":         {},
+		"b/b1.test":       {},
+		"b/c/d/d1.test":   {},
+		"b/c/d/d2.test":   {},
+		"e/f/g/h/h1.test": {},
+	}
+
+	tfs, err := New(mapfs, ".", DeepestReport(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []PathDepth{
+		{Path: "e/f/g/h/h1.test", Depth: 5},
+		{Path: "b/c/d/d1.test", Depth: 4},
+		{Path: "b/c/d/d2.test", Depth: 4},
+	}
+	if got := tfs.Deepest(3); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	for _, n := range []int{0, -1} {
+		if got := tfs.Deepest(n); got != nil {
+			t.Fatalf("Deepest(%d): expected nil, got %v", n, got)
+		}
+	}
+
+	compare(t, tfs.String(), `
+.
+├── a1.test
+├── b
+│   ├── b1.test
+│   └── c
+│       └── d
+│           ├── d1.test
+│           └── d2.test
+└── e
+    └── f
+        └── g
+            └── h
+                └── h1.test
+
+7 directories, 5 files
+
+deepest paths:
+   5  e/f/g/h/h1.test
+   4  b/c/d/d1.test`[1:])
+}
+
+func TestLargest(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.bin": {Data: make([]byte, 3000)},
+		"a/a2.txt": {Data: []byte("hello")},
+		"b.bin":    {Data: make([]byte, 3000)},
+		"c.txt":    {Data: []byte("hi")},
+	}
+
+	tfs, err := New(mapfs, ".", LargestReport(2), NoReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []PathSize{
+		{Path: "a/a1.bin", Size: 3000},
+		{Path: "b.bin", Size: 3000},
+		{Path: "a/a2.txt", Size: 5},
+	}
+	if got := tfs.Largest(3); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	for _, n := range []int{0, -1} {
+		if got := tfs.Largest(n); got != nil {
+			t.Fatalf("Largest(%d): expected nil, got %v", n, got)
+		}
+	}
+
+	compare(t, tfs.String(), `
+.
+├── a
+│   ├── a1.bin
+│   └── a2.txt
+├── b.bin
+└── c.txt
+
+largest files:
+2.9K  a/a1.bin
+2.9K  b.bin`[1:])
+}
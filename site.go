@@ -0,0 +1,165 @@
+package treefs
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sitePage is the template of each page written by WriteSite.
+var sitePage = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+<h1>{{range .Crumbs}}<a href="{{.Href}}">{{.Name}}</a> / {{end}}{{.Name}}</h1>
+<ul>
+{{- if .Crumbs}}
+<li><a href="../index.html">..</a></li>
+{{- end}}
+{{- range .Entries}}
+<li>{{if .Href}}<a href="{{.Href}}">{{.Name}}/</a>{{else}}{{.Name}}{{end}}</li>
+{{- end}}
+</ul>
+<p>{{.Meta}}</p>
+</body>
+</html>
+`))
+
+// siteLink is a named link of a page written by WriteSite. Entries that are
+// not directories have no Href.
+type siteLink struct {
+	Name string
+	Href string
+}
+
+// WriteSite writes a browsable static HTML listing of the TreeFS t to the
+// directory dir, with one index.html page per walked directory linking to
+// its parent and subdirectories.
+//
+// The site is generated from the already walked tree, so fsys is not read
+// again. If t graphs several fs.FS, as with NewMulti, each is written to a
+// numbered subdirectory of dir, linked from dir/index.html.
+//
+// The page of each directory is written to the subdirectory of the same name,
+// except for directories named index.html, whose page would otherwise
+// overwrite the page of their parent, and those whose name starts with "~",
+// which are written to a subdirectory named with an extra leading "~".
+func (t TreeFS) WriteSite(dir string) error {
+	if len(t.roots) == 1 {
+		return t.writePages(dir, t.roots[0], nil)
+	}
+
+	var roots []siteLink
+	for i, root := range t.roots {
+		sub := strconv.Itoa(i)
+		if err := t.writePages(filepath.Join(dir, sub), root, nil); err != nil {
+			return err
+		}
+		roots = append(roots, siteLink{Name: root.Name, Href: sub + "/index.html"})
+	}
+	return writePage(dir, map[string]any{
+		"Title":   "treefs",
+		"Name":    "treefs",
+		"Entries": roots,
+		"Meta":    t.Meta(),
+	})
+}
+
+// Recursively write the pages of the directory Node n, and those below it,
+// to dir. The names of the ancestors of n, from the root down, are crumbs.
+func (t TreeFS) writePages(dir string, n *Node, crumbs []string) error {
+	var entries []siteLink
+	for _, child := range n.Children {
+		link := siteLink{Name: child.Name}
+		if child.IsDir {
+			link.Href = url.PathEscape(siteDir(child.Name)) + "/index.html"
+		}
+		entries = append(entries, link)
+	}
+
+	// Each crumb links up as many levels as it is above n.
+	var links []siteLink
+	for i, crumb := range crumbs {
+		up := strings.Repeat("../", len(crumbs)-i)
+		links = append(links, siteLink{Name: crumb, Href: up + "index.html"})
+	}
+
+	meta := plural(n.ndirs, "directory", "directories") + ", " + plural(n.nfiles, "file", "files")
+	if err := writePage(dir, map[string]any{
+		"Title":   n.Path,
+		"Name":    n.Name,
+		"Crumbs":  links,
+		"Entries": entries,
+		"Meta":    meta,
+	}); err != nil {
+		return err
+	}
+
+	crumbs = append(crumbs[:len(crumbs):len(crumbs)], n.Name)
+	for _, child := range n.Children {
+		if !child.IsDir {
+			continue
+		}
+		// Nodes that weren't walked, e.g. decoded by UnmarshalJSON, could
+		// otherwise be written outside of dir.
+		if !validName(child.Name) {
+			return &fs.PathError{Op: "write", Path: child.Path, Err: ErrInvalidName}
+		}
+		if err := t.writePages(filepath.Join(dir, siteDir(child.Name)), child, crumbs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write the index.html page with the data data to dir, creating dir if
+// needed.
+func writePage(dir string, data map[string]any) (err error) {
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+
+	f, err := os.Create(filepath.Join(dir, "index.html"))
+	if err != nil {
+		return
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	return sitePage.Execute(f, data)
+}
+
+// Return the name of the subdirectory of the site written by WriteSite that
+// holds the page of the directory with name name. Every name maps to its own
+// subdirectory.
+func siteDir(name string) string {
+	if strings.EqualFold(name, "index.html") || strings.HasPrefix(name, "~") {
+		return "~" + name
+	}
+	return name
+}
+
+// Report whether name is a single element of a path, as the names of walked
+// entries are, rather than ".", ".." or a path of several elements.
+func validName(name string) bool {
+	return fs.ValidPath(name) && name != "." && !strings.ContainsAny(name, `/\`)
+}
+
+// Format the count n followed by singular or plural, depending on n.
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
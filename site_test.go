@@ -0,0 +1,110 @@
+package treefs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestWriteSite(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a1.test":      {},
+		"b/b1.test":    {},
+		"b/c d/c.test": {},
+	}
+
+	tfs, err := New(mapfs, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := tfs.WriteSite(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for page, contains := range map[string][]string{
+		"index.html": {
+			`<li>a1.test</li>`,
+			`<li><a href="b/index.html">b/</a></li>`,
+			`<p>2 directories, 3 files</p>`,
+		},
+		"b/index.html": {
+			`<h1><a href="../index.html">.</a> / b</h1>`,
+			`<li><a href="../index.html">..</a></li>`,
+			`<li><a href="c%20d/index.html">c d/</a></li>`,
+		},
+		"b/c d/index.html": {
+			`<h1><a href="../../index.html">.</a> / <a href="../index.html">b</a> / c d</h1>`,
+			`<li>c.test</li>`,
+			`<p>0 directories, 1 file</p>`,
+		},
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(page)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range contains {
+			if !strings.Contains(string(data), s) {
+				t.Errorf("%s: expected to contain %q, got:\n%s", page, s, data)
+			}
+		}
+	}
+}
+
+func TestWriteSiteIndexDir(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"index.html/a.test":   {},
+		"~index.html/b.test":  {},
+		"~~index.html/c.test": {},
+	}
+
+	tfs, err := New(mapfs, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := tfs.WriteSite(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for page, contains := range map[string][]string{
+		"index.html": {
+			`<li><a href="~index.html/index.html">index.html/</a></li>`,
+			`<li><a href="~~index.html/index.html">~index.html/</a></li>`,
+			`<li><a href="~~~index.html/index.html">~~index.html/</a></li>`,
+		},
+		"~index.html/index.html":   {`<li>a.test</li>`},
+		"~~index.html/index.html":  {`<li>b.test</li>`},
+		"~~~index.html/index.html": {`<li>c.test</li>`},
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(page)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range contains {
+			if !strings.Contains(string(data), s) {
+				t.Errorf("%s: expected to contain %q, got:\n%s", page, s, data)
+			}
+		}
+	}
+}
+
+func TestWriteSiteInvalidName(t *testing.T) {
+	for _, name := range []string{"..", ".", "../../escaped", `a\b`} {
+		root := &Node{Name: ".", Path: ".", IsDir: true}
+		root.Children = []*Node{{Name: name, Path: "x", IsDir: true, depth: 1}}
+		tfs := TreeFS{roots: []*Node{root}}
+
+		dir := t.TempDir()
+		err := tfs.WriteSite(filepath.Join(dir, "site", "out"))
+		if !errors.Is(err, ErrInvalidName) {
+			t.Errorf("%q: expected %v, got %v", name, ErrInvalidName, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "escaped")); err == nil {
+			t.Errorf("%q: expected nothing to be written outside of the site", name)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package treefs
+
+import "fmt"
+
+// Size displays the size of each entry in bytes, like `tree -s`.
+func Size(t *TreeFS) {
+	t.sizeFmt = rawSize
+}
+
+// HumanSize displays the size of each entry in a human readable format,
+// scaling to the most suitable power of 1024, like `tree -h`.
+func HumanSize(t *TreeFS) {
+	t.sizeFmt = humanSize
+}
+
+// SizeUnit is a unit that sizes can be displayed in using FixedSize.
+type SizeUnit int64
+
+const (
+	Bytes SizeUnit = 1
+	KiB   SizeUnit = 1 << 10
+	MiB   SizeUnit = 1 << 20
+	GiB   SizeUnit = 1 << 30
+)
+
+// String implements the stringer interface for SizeUnit.
+func (u SizeUnit) String() string {
+	switch u {
+	case Bytes:
+		return "B"
+	case KiB:
+		return "KiB"
+	case MiB:
+		return "MiB"
+	case GiB:
+		return "GiB"
+	}
+	return fmt.Sprintf("SizeUnit(%d)", int64(u))
+}
+
+// FixedSize displays the size of each entry in the unit unit with prec
+// decimal places, rather than auto-scaling like HumanSize.
+//
+// Every size is padded to the same width so that columns line up and
+// generated reports diff cleanly.
+func FixedSize(unit SizeUnit, prec int) Opt {
+	prec = max(prec, 0)
+	return func(t *TreeFS) {
+		// Ignore if unit is not a positive unit.
+		if unit <= 0 {
+			return
+		}
+		t.sizeFmt = func(size int64) string {
+			return fmt.Sprintf("%11.*f %s", prec, float64(size)/float64(unit), unit)
+		}
+	}
+}
+
+// Format size the same way `tree -s` does.
+func rawSize(size int64) string {
+	return fmt.Sprintf("%11d", size)
+}
+
+// Format size the same way `tree -h` does.
+func humanSize(size int64) string {
+	const units = "BKMGTPEZY"
+
+	idx := 0
+	if size >= 1024 {
+		idx = 1
+	}
+	for ; size >= 1024*1024; idx++ {
+		size /= 1024
+	}
+	if idx == 0 {
+		return fmt.Sprintf("%4d", size)
+	}
+
+	scaled := float64(size) / 1024
+	if size/1024 >= 10 {
+		return fmt.Sprintf("%3.0f%c", scaled, units[idx])
+	}
+	return fmt.Sprintf("%3.1f%c", scaled, units[idx])
+}
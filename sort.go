@@ -0,0 +1,190 @@
+package treefs
+
+import (
+	"cmp"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// SortByDescendants orders the entries of each directory by their total
+// number of descendants, most first, so that the heaviest parts of a tree
+// float to the top of each level.
+//
+// Entries with the same number of descendants, such as files, keep their
+// relative order.
+func SortByDescendants(t *TreeFS) {
+	t.cmp = func(a, b *Node) int {
+		return (b.ndirs + b.nfiles) - (a.ndirs + a.nfiles)
+	}
+}
+
+// SortBySize orders the entries of each directory by size, largest first,
+// like tree --sort=size.
+//
+// Entries of the same size, and those whose size is unknown, keep their
+// relative order after the others.
+func SortBySize(t *TreeFS) {
+	t.cmp = func(a, b *Node) int {
+		return cmpSize(b, a)
+	}
+}
+
+// Compare the sizes of the Nodes a and b, unknown sizes being the smallest.
+func cmpSize(a, b *Node) int {
+	size := func(n *Node) int64 {
+		fi, err := n.info()
+		if err != nil {
+			return -1
+		}
+		return fi.Size()
+	}
+	return cmp.Compare(size(a), size(b))
+}
+
+// VersionSort orders the entries of each directory naturally, like tree -v,
+// so that numbers within names compare by value, e.g. "file2" sorts before
+// "file10" and "v1.9" before "v1.10".
+func VersionSort(t *TreeFS) {
+	t.cmp = func(a, b *Node) int {
+		return cmpVersion(a.Name, b.Name)
+	}
+}
+
+// SortIgnoreCase orders the entries of each directory by name regardless of
+// case, so that e.g. "README.md" and "readme_test.go" sort next to each other
+// rather than upper case names sorting first.
+func SortIgnoreCase(t *TreeFS) {
+	t.cmp = func(a, b *Node) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	}
+}
+
+// Collate orders the entries of each directory by name using compare, so
+// that non-ASCII names can be sorted the way users of a locale expect with a
+// Unicode collator, without this package depending on one:
+//
+//	c := collate.New(language.Swedish)
+//	tfs, err := treefs.New(fsys, ".", treefs.Collate(c.CompareString))
+//
+// where collate and language are golang.org/x/text/collate and
+// golang.org/x/text/language. compare must return a negative number, zero or
+// a positive number if a sorts before, with, or after b.
+func Collate(compare func(a, b string) int) Opt {
+	return func(t *TreeFS) {
+		// Ignore if compare is nil.
+		if compare == nil {
+			return
+		}
+		t.cmp = func(a, b *Node) int {
+			return compare(a.Name, b.Name)
+		}
+	}
+}
+
+// Compare the strings a and b, with runs of digits compared by value.
+func cmpVersion(a, b string) int {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			i, j := digits(a), digits(b)
+			na, nb := strings.TrimLeft(a[:i], "0"), strings.TrimLeft(b[:j], "0")
+			// Longer numbers are greater, once leading zeros are dropped.
+			if c := cmp.Compare(len(na), len(nb)); c != 0 {
+				return c
+			}
+			if c := strings.Compare(na, nb); c != 0 {
+				return c
+			}
+			a, b = a[i:], b[j:]
+			continue
+		}
+		if a[0] != b[0] {
+			return cmp.Compare(a[0], b[0])
+		}
+		a, b = a[1:], b[1:]
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+// Return the length of the run of digits at the start of s.
+func digits(s string) int {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return i
+}
+
+// NoSort lists the entries of each directory in the order the fs.FS returns
+// them, like tree -U, rather than the order of fs.ReadDir, which sorts them
+// by name. It is faster for large directories.
+//
+// Orders set by other Opts, such as SortByDescendants, still apply.
+func NoSort(t *TreeFS) {
+	t.unsorted = true
+}
+
+// SortEntries sorts the entries of each directory by name itself, since
+// fs.FS implementations of fs.ReadDirFS aren't guaranteed to, which makes the
+// graph deterministic for any fs.FS. It overrides NoSort.
+//
+// Orders set by other Opts, such as SortByDescendants, are applied on top,
+// with ties ordered by name.
+func SortEntries(t *TreeFS) {
+	t.sortNames = true
+}
+
+// Read the entries of the directory with name name, in the order set by t.
+func (t *TreeFS) readEntries(name string) ([]fs.DirEntry, error) {
+	if !t.unsorted || t.sortNames {
+		entries, err := fs.ReadDir(t.fsys, name)
+		if t.sortNames {
+			sort.SliceStable(entries, func(i, j int) bool {
+				return entries[i].Name() < entries[j].Name()
+			})
+		}
+		return entries, err
+	}
+
+	f, err := t.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dir, ok := f.(fs.ReadDirFile)
+	if !ok {
+		// Let fs.ReadDir report why name can't be read.
+		return fs.ReadDir(t.fsys, name)
+	}
+	return dir.ReadDir(-1)
+}
+
+// DirsFirst lists the subdirectories of each directory before its files,
+// like tree --dirsfirst. Each group keeps the order set by other Opts.
+func DirsFirst(t *TreeFS) {
+	t.dirsFirst, t.filesFirst = true, false
+}
+
+// FilesFirst lists the files of each directory before its subdirectories,
+// like tree --filesfirst. Each group keeps the order set by other Opts.
+func FilesFirst(t *TreeFS) {
+	t.dirsFirst, t.filesFirst = false, true
+}
+
+// Stably sort the entries of a directory using t's comparison, if any, then
+// group them by type.
+func (t *TreeFS) sort(entries []*Node) {
+	if t.cmp != nil {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return t.cmp(entries[i], entries[j]) < 0
+		})
+	}
+	if t.dirsFirst || t.filesFirst {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].IsDir == t.dirsFirst && entries[j].IsDir != t.dirsFirst
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package treefs
+
+import (
+	"io/fs"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+// reverseFS wraps an fstest.MapFS, returning the entries of directories in
+// reverse order.
+type reverseFS struct {
+	fstest.MapFS
+}
+
+func (r reverseFS) Open(name string) (fs.File, error) {
+	f, err := r.MapFS.Open(name)
+	if dir, ok := f.(fs.ReadDirFile); ok {
+		return reverseDir{dir}, err
+	}
+	return f, err
+}
+
+func (r reverseFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := r.MapFS.ReadDir(name)
+	slices.Reverse(entries)
+	return entries, err
+}
+
+type reverseDir struct {
+	fs.ReadDirFile
+}
+
+func (r reverseDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	entries, err := r.ReadDirFile.ReadDir(n)
+	slices.Reverse(entries)
+	return entries, err
+}
+
+func TestSortOrder(t *testing.T) {
+	mapfs := reverseFS{fstest.MapFS{
+		"a/a1.test":   {},
+		"a/a2.test":   {},
+		"a/b/b1.test": {},
+	}}
+	sorted := `
+a
+├── a1.test
+├── a2.test
+└── b
+    └── b1.test`[1:]
+	reversed := `
+a
+├── b
+│   └── b1.test
+├── a2.test
+└── a1.test`[1:]
+
+	tests := []struct {
+		name     string
+		fsys     fs.FS
+		opts     []Opt
+		expected string
+	}{
+		// fs.ReadDir trusts implementations of fs.ReadDirFS to sort.
+		{"fs.ReadDirFS", mapfs, nil, reversed},
+		{"fs.ReadDirFS sorted", mapfs, []Opt{SortEntries}, sorted},
+		{"fs.ReadDirFS unsorted", mapfs, []Opt{NoSort}, reversed},
+		// Otherwise, it sorts the entries itself.
+		{"fs.FS", struct{ fs.FS }{mapfs}, nil, sorted},
+		{"fs.FS sorted", struct{ fs.FS }{mapfs}, []Opt{SortEntries}, sorted},
+		{"fs.FS unsorted", struct{ fs.FS }{mapfs}, []Opt{NoSort}, reversed},
+		{"fs.FS both", struct{ fs.FS }{mapfs}, []Opt{NoSort, SortEntries}, sorted},
+	}
+	for _, tc := range tests {
+		tfs, err := New(tc.fsys, "a", tc.opts...)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got := tfs.Graph(); got != tc.expected {
+			t.Errorf("%s: expected:\n%s\ngot:\n%s", tc.name, tc.expected, got)
+		}
+	}
+}
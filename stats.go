@@ -0,0 +1,69 @@
+package treefs
+
+import "path"
+
+// Stats are statistics of the entries walked into a TreeFS, for callers that
+// build dashboards or checks without walking the fs.FS a second time.
+type Stats struct {
+	Dirs  int   // the number of directories, excluding the roots
+	Files int   // the number of other entries, e.g. files and symbolic links
+	Bytes int64 // the total size of the files whose size is known
+
+	// The number of files by extension, e.g. ".go", with the empty string
+	// for files without one.
+	Extensions map[string]int
+
+	// The number of entries by depth, the roots being at depth 0, so that
+	// len(Depths)-1 is the max depth.
+	Depths []int
+}
+
+// Stats returns the statistics of the entries walked into t, including
+// those that are walked but not displayed, e.g. because of Paginate.
+func (t TreeFS) Stats() Stats {
+	s := Stats{Extensions: map[string]int{}, Depths: t.DepthHistogram()}
+	var visit func(n *Node)
+	visit = func(n *Node) {
+		if n.IsDir {
+			if n.depth > 0 {
+				s.Dirs++
+			}
+			for _, child := range n.Children {
+				visit(child)
+			}
+			return
+		}
+
+		s.Files++
+		s.Extensions[path.Ext(n.Name)]++
+		if fi, err := n.info(); err == nil && fi != nil && fi.Mode().IsRegular() {
+			s.Bytes += fi.Size()
+		}
+	}
+	for _, root := range t.roots {
+		visit(root)
+	}
+	return s
+}
+
+// DepthHistogram returns the number of entries walked into t by depth, the
+// roots being at depth 0, for quick structural sanity checks, e.g. that an
+// extracted archive has a single top-level directory. The max depth is the
+// length of the histogram minus one.
+func (t TreeFS) DepthHistogram() []int {
+	var hist []int
+	var visit func(n *Node)
+	visit = func(n *Node) {
+		if len(hist) <= n.depth {
+			hist = append(hist, 0)
+		}
+		hist[n.depth]++
+		for _, child := range n.Children {
+			visit(child)
+		}
+	}
+	for _, root := range t.roots {
+		visit(root)
+	}
+	return hist
+}
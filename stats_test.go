@@ -0,0 +1,86 @@
+package treefs
+
+import (
+	"io/fs"
+	"reflect"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+func TestStats(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.go":    {Data: []byte("package a")},
+		"a/b/b1.go":  {Data: []byte("package b")},
+		"a/b/README": {Data: []byte("hi")},
+		"a/link":     {Data: []byte("a1.go"), Mode: fs.ModeSymlink},
+		"go.mod":     {Data: []byte("module x")},
+	}
+
+	tfs, err := New(mapfs, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Stats{
+		Dirs:       2,
+		Files:      5,
+		Bytes:      28,
+		Extensions: map[string]int{".go": 2, "": 2, ".mod": 1},
+		Depths:     []int{1, 2, 3, 2},
+	}
+	if got := tfs.Stats(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestDepthHistogram(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/b/c/c1.test": {},
+		"a/b/b1.test":   {},
+		"d.test":        {},
+	}
+
+	tfs, err := NewMulti(
+		Arg{Fsys: mapfs, Name: "."},
+		Arg{Fsys: mapfs, Name: "a/b"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []int{2, 4, 2, 2, 1}
+	if got := tfs.DepthHistogram(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+// The accessors load the Info of Nodes lazily, so they must be safe to call
+// concurrently; run with -race.
+func TestStatsConcurrent(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.go":   {Data: []byte("package a")},
+		"a/b/b1.go": {Data: []byte("package b")},
+		"a/b/c/c1":  {Data: []byte("c")},
+	}
+	tfs, err := New(mapfs, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for _, fn := range []func(){
+		func() { tfs.Stats() },
+		func() { tfs.Largest(3) },
+		func() { tfs.Root() },
+		func() { _ = tfs.JSON() },
+		func() { _ = tfs.XML() },
+	} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn()
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,89 @@
+package treefs
+
+import "io"
+
+// Stream writes every line of the graph to w, followed by a newline, as soon
+// as the directory of its entry is read, rather than once the whole fs.FS
+// has been walked. The Nodes of each directory are dropped once written and
+// no lines are kept, so memory is bounded by the depth and width of the fs.FS
+// rather than its size, even for filesystems with millions of entries.
+//
+// Lines are written unbuffered, so wrap w in a bufio.Writer if it is slow.
+// Like with OnLine, the Graph of the resulting TreeFS is empty and its String
+// only contains the metadata. Since no Nodes are kept, its Root has no
+// Children, so the accessors built on them, such as Walk, All, Stats,
+// Deepest, Largest, JSON and XML, find no entries. NumEntries and the
+// metadata still count every entry.
+//
+// Opts that need the whole subtree of a directory before rendering it are
+// ignored: the pruning of empty directories by Prune and filters such as
+// MinSize, Collapse, DiskUsage, DirCounts, FoldIdentical, DedupeIdentical,
+// DepthRange, Paginate, CountAll, CountVisibleOnly and the alignment of
+// Annotations.
+//
+// If writing to w fails, the walk stops and New returns the error.
+func Stream(w io.Writer) Opt {
+	return func(t *TreeFS) {
+		t.stream = w
+	}
+}
+
+// Turn off the Opts of t that can't be applied while streaming.
+func (t *TreeFS) unstreamable() {
+	t.prune = false
+	t.collapsed = nil
+	t.du = false
+	t.dirCounts = false
+	t.foldIdentical = false
+	t.dedupeIdentical = false
+	t.minDepth = 0
+	t.pageSize = 0
+	t.countAll = false
+	t.countVisible = false
+}
+
+// Write the line to the io.Writer set by Stream, unless a previous write
+// failed.
+func (t *TreeFS) writeStream(line string) {
+	if t.streamErr != nil {
+		return
+	}
+	_, t.streamErr = io.WriteString(t.stream, line+"\n")
+}
+
+// Write the Children of the directory Node n, which have just been read, to
+// the io.Writer set by Stream, walking each deferred subdirectory right
+// after its own line, then drop them.
+func (t *TreeFS) streamDir(n *Node) (err error) {
+	prefix := ""
+	if len(t.prefixes) > 0 {
+		prefix = t.prefixes[len(t.prefixes)-1]
+	}
+
+	for i, child := range n.Children {
+		connector, childPrefix := t.style.Tee, prefix+t.paintConnector(t.style.Pipe, child.depth)
+		if i == len(n.Children)-1 {
+			connector, childPrefix = t.style.Elbow, prefix+t.style.Space
+		}
+		t.append(prefix, t.paintConnector(connector, child.depth), child, "")
+		if t.streamErr != nil {
+			return t.streamErr
+		}
+
+		if !child.deferred {
+			continue
+		}
+		t.prefixes = append(t.prefixes, childPrefix)
+		err = t.walk(child)
+		t.prefixes = t.prefixes[:len(t.prefixes)-1]
+		if err != nil {
+			return
+		}
+		// child was added to n before it was walked.
+		n.ndirs += child.ndirs
+		n.nfiles += child.nfiles
+	}
+
+	n.Children = nil
+	return
+}
@@ -0,0 +1,73 @@
+package treefs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestStream(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.test":         {},
+		"a/b/b1.test":       {},
+		"a/b/c/c1.test":     {},
+		"a/b/c/c2.test":     {},
+		"a/d/d1.test":       {},
+		"a/e/.hidden.test":  {},
+		"a/e/f/g/g1.test":   {},
+		"a/z.test":          {Data: []byte("z")},
+		"a/y/y1.test":       {},
+		"a/y/y2/y21.test":   {},
+		"a/y/y2/y22/.empty": {},
+	}
+
+	for _, opts := range [][]Opt{
+		nil,
+		{Hidden},
+		{Level(2)},
+		{DirsFirst, Size},
+		{FullPathPrefix, Classify},
+		{MaxNodes(5)},
+	} {
+		expected, eerr := New(mapfs, "a", opts...)
+
+		var b strings.Builder
+		tfs, err := New(mapfs, "a", append(opts, Stream(&b))...)
+		if fmt.Sprint(err) != fmt.Sprint(eerr) {
+			t.Fatalf("expected %v, got %v", eerr, err)
+		}
+		compare(t, b.String(), expected.Graph()+"\n")
+		compare(t, tfs.String(), expected.Meta())
+		if tfs.Graph() != "" {
+			t.Errorf("expected an empty graph, got %q", tfs.Graph())
+		}
+		if root := tfs.Root(); len(root.Children) != 0 {
+			t.Errorf("expected the Nodes to be dropped, got %d children", len(root.Children))
+		}
+		if tfs.NumEntries() != expected.NumEntries() {
+			t.Errorf("expected %d entries, got %d", expected.NumEntries(), tfs.NumEntries())
+		}
+		// The accessors built on the Nodes find no entries.
+		if stats := tfs.Stats(); stats.Dirs != 0 || stats.Files != 0 {
+			t.Errorf("expected empty Stats, got %+v", stats)
+		}
+		if largest := tfs.Largest(1); len(largest) != 0 {
+			t.Errorf("expected no largest files, got %v", largest)
+		}
+	}
+
+	// Opts that need whole subtrees are ignored.
+	var b strings.Builder
+	if _, err := New(mapfs, "a", Match("c1.test"), Prune, Paginate(1), Stream(&b)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "├── d\n") {
+		t.Errorf("expected empty directories to be written, got %q", b.String())
+	}
+
+	if _, err := New(mapfs, "a", Stream(failWriter{})); !errors.Is(err, errWrite) {
+		t.Errorf("expected %v, got %v", errWrite, err)
+	}
+}
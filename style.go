@@ -0,0 +1,49 @@
+package treefs
+
+// Style is the set of glyphs a graph is drawn with.
+type Style struct {
+	Tee   string // connector of entries followed by a sibling
+	Elbow string // connector of the last entry of a directory
+	Pipe  string // prefix continuing the connector of a Tee
+	Space string // prefix continuing the connector of an Elbow
+}
+
+// Predefined Styles.
+var (
+	// DefaultStyle is the Style of `tree`.
+	DefaultStyle = Style{Tee: teeConnector, Elbow: elbowConnector, Pipe: pipePrefix, Space: spacePrefix}
+
+	// ASCIIStyle is the Style of `tree --charset=ascii`, for terminals and
+	// files that can't display box-drawing characters.
+	ASCIIStyle = Style{Tee: "|--", Elbow: "`--", Pipe: "|   ", Space: "    "}
+
+	// RoundedStyle draws the last entry of each directory with a rounded
+	// corner.
+	RoundedStyle = Style{Tee: "├──", Elbow: "╰──", Pipe: "│   ", Space: "    "}
+
+	// DoubleStyle draws the graph with double lines.
+	DoubleStyle = Style{Tee: "╠══", Elbow: "╚══", Pipe: "║   ", Space: "    "}
+)
+
+// WithStyle draws the graph with the glyphs of s. Empty glyphs are those of
+// DefaultStyle.
+//
+// Pipe and Space should be as wide as the connectors followed by a space so
+// that the entries of each directory line up.
+func WithStyle(s Style) Opt {
+	if s.Tee == "" {
+		s.Tee = DefaultStyle.Tee
+	}
+	if s.Elbow == "" {
+		s.Elbow = DefaultStyle.Elbow
+	}
+	if s.Pipe == "" {
+		s.Pipe = DefaultStyle.Pipe
+	}
+	if s.Space == "" {
+		s.Space = DefaultStyle.Space
+	}
+	return func(t *TreeFS) {
+		t.style = s
+	}
+}
@@ -0,0 +1,100 @@
+package treefs
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// Geometry of the graph drawn by SVG, in pixels.
+const (
+	svgRowHeight = 20 // height of the row of each entry
+	svgIndent    = 24 // horizontal offset of each depth
+	svgPadding   = 10 // margin around the graph
+	svgFontSize  = 14 // font size of names
+)
+
+// svgStyle is the default stylesheet of the graph drawn by SVG.
+const svgStyle = `text { font-family: monospace; font-size: 14px; fill: #24292f; }
+.dir { font-weight: bold; fill: #0550ae; }
+.meta { fill: #57606a; }
+.connector { stroke: #8c959f; stroke-width: 1; fill: none; }`
+
+// SVG returns an SVG image of the graph of the TreeFS t, drawn with lines
+// rather than box-drawing characters so that it scales with the page it is
+// embedded in.
+//
+// Names of directories have the "dir" class, those of other entries the
+// "file" class, the lines connecting them the "connector" class and the
+// metadata the "meta" class. The stylesheet css is appended to the default
+// one, so it can override any of them.
+func (t TreeFS) SVG(css string) string {
+	var body strings.Builder
+	row, width := 0, 0
+
+	var draw func(n *Node, depth int)
+	draw = func(n *Node, depth int) {
+		x := svgPadding + depth*svgIndent
+		y := svgPadding + row*svgRowHeight
+		row++
+
+		class := "file"
+		if n.IsDir {
+			class = "dir"
+		}
+		name := n.Name
+		if info := t.info(n); info != "" {
+			name = info + "  " + name
+		}
+		fmt.Fprintf(&body, "<text class=%q x=\"%d\" y=\"%d\">%s</text>\n",
+			class, x, y+svgRowHeight*3/4, html.EscapeString(name))
+		if w := x + utf8.RuneCountInString(name)*svgFontSize*3/5; w > width {
+			width = w
+		}
+
+		// The vertical line below n spans from its row to the middle of
+		// the row of its last child.
+		lineX := x + svgIndent/3
+		var lastY int
+		for _, child := range n.Children {
+			childY := svgPadding + row*svgRowHeight + svgRowHeight/2
+			fmt.Fprintf(&body, "<path class=\"connector\" d=\"M%d %dH%d\"/>\n",
+				lineX, childY, x+svgIndent-4)
+			lastY = childY
+			draw(child, depth+1)
+		}
+		if len(n.Children) > 0 {
+			fmt.Fprintf(&body, "<path class=\"connector\" d=\"M%d %dV%d\"/>\n",
+				lineX, y+svgRowHeight, lastY)
+		}
+	}
+	for _, root := range t.roots {
+		draw(root, 0)
+	}
+
+	// The metadata follows the graph after an empty row, like in String.
+	row++
+	meta := t.Meta()
+	fmt.Fprintf(&body, "<text class=\"meta\" x=\"%d\" y=\"%d\">%s</text>\n",
+		svgPadding, svgPadding+row*svgRowHeight+svgRowHeight*3/4, html.EscapeString(meta))
+	if w := svgPadding + utf8.RuneCountInString(meta)*svgFontSize*3/5; w > width {
+		width = w
+	}
+	row++
+
+	width += svgPadding
+	height := row*svgRowHeight + 2*svgPadding
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		width, height, width, height)
+	b.WriteString("<style>\n" + svgStyle + "\n")
+	if css != "" {
+		b.WriteString(strings.TrimSuffix(css, "\n") + "\n")
+	}
+	b.WriteString("</style>\n")
+	b.WriteString(body.String())
+	b.WriteString("</svg>")
+	return b.String()
+}
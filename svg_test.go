@@ -0,0 +1,49 @@
+package treefs
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSVG(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.test":   {},
+		"a/b/<b1>":    {},
+		"a/b/b2.test": {},
+	}
+	tfs, err := New(mapfs, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+<svg xmlns="http://www.w3.org/2000/svg" width="188" height="160" viewBox="0 0 188 160">
+<style>
+text { font-family: monospace; font-size: 14px; fill: #24292f; }
+.dir { font-weight: bold; fill: #0550ae; }
+.meta { fill: #57606a; }
+.connector { stroke: #8c959f; stroke-width: 1; fill: none; }
+.dir { fill: purple; }
+</style>
+<text class="dir" x="10" y="25">a</text>
+<path class="connector" d="M18 40H30"/>
+<text class="file" x="34" y="45">a1.test</text>
+<path class="connector" d="M18 60H30"/>
+<text class="dir" x="34" y="65">b</text>
+<path class="connector" d="M42 80H54"/>
+<text class="file" x="58" y="85">&lt;b1&gt;</text>
+<path class="connector" d="M42 100H54"/>
+<text class="file" x="58" y="105">b2.test</text>
+<path class="connector" d="M42 70V100"/>
+<path class="connector" d="M18 30V60"/>
+<text class="meta" x="10" y="145">1 directory, 3 files</text>
+</svg>`[1:]
+	got := tfs.SVG(".dir { fill: purple; }\n")
+	compare(t, got, expected)
+
+	if err := xml.NewDecoder(strings.NewReader(got)).Decode(new(struct{})); err != nil {
+		t.Fatalf("invalid SVG: %v", err)
+	}
+}
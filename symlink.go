@@ -0,0 +1,80 @@
+package treefs
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// BrokenLinks annotates symbolic links whose target does not resolve within
+// the fs.FS with "[broken]", since dangling links are usually exactly what
+// people look for in these listings.
+func BrokenLinks(t *TreeFS) {
+	t.brokenLinks = true
+}
+
+// FollowSymlinks descends into symbolic links to directories, like tree -l.
+//
+// Targets are only known if the fs.FS implements fs.ReadLinkFS. Links that
+// point to a directory being walked, which would make the walk infinite, are
+// annotated with "[recursive, not followed]" instead.
+func FollowSymlinks(t *TreeFS) {
+	t.followSymlinks = true
+}
+
+// Report whether the Node n is a symbolic link whose target does not exist.
+func (t *TreeFS) broken(n *Node) bool {
+	if n.entry == nil || n.entry.Type()&fs.ModeSymlink == 0 {
+		return false
+	}
+	// fs.Stat follows links, so it fails for a link that doesn't resolve.
+	_, err := fs.Stat(t.fsys, n.Path)
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+// Return the target of the symbolic link Node n, if the fs.FS implements
+// fs.ReadLinkFS, or an empty string.
+func (t *TreeFS) readLink(n *Node) string {
+	if _, ok := t.fsys.(fs.ReadLinkFS); !ok {
+		return ""
+	}
+	target, err := fs.ReadLink(t.fsys, n.Path)
+	if err != nil {
+		if t.strict {
+			t.fail("readlink", n.Path, err)
+		}
+		return ""
+	}
+	return target
+}
+
+// Mark the symbolic link Node n as a directory if its target is one, so that
+// it is walked, or as a loop if its target is being walked already.
+//
+// Absolute targets, and those outside of the fs.FS, can't be resolved.
+func (t *TreeFS) follow(n *Node) {
+	if n.Target == "" || path.IsAbs(n.Target) {
+		return
+	}
+	real := path.Join(path.Dir(n.realPath()), n.Target)
+	if !fs.ValidPath(real) {
+		return
+	}
+	fi, err := fs.Stat(t.fsys, real)
+	if err != nil || !fi.IsDir() {
+		return
+	}
+
+	n.IsDir = true
+	n.real = real
+	for _, p := range t.visiting {
+		if real == "." || p == real || strings.HasPrefix(p, real+"/") {
+			if t.loops == nil {
+				t.loops = make(map[*Node]bool)
+			}
+			t.loops[n] = true
+			return
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package treefs
+
+import (
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+// FileTimes extracts the times of a file that fs.FileInfo does not expose
+// directly, usually from FileInfo.Sys().
+type FileTimes interface {
+	// ATime returns the last access time of fi, and whether it is known.
+	ATime(fi fs.FileInfo) (time.Time, bool)
+	// CTime returns the last status change time of fi, and whether it is
+	// known.
+	CTime(fi fs.FileInfo) (time.Time, bool)
+}
+
+// ShowMTime displays the last modification time of each entry.
+func ShowMTime(t *TreeFS) {
+	t.mtime = true
+}
+
+// ShowATime displays the last access time of each entry, when the FileTimes
+// in use can extract it.
+func ShowATime(t *TreeFS) {
+	t.atime = true
+}
+
+// ShowCTime displays the last status change time of each entry, when the
+// FileTimes in use can extract it.
+func ShowCTime(t *TreeFS) {
+	t.ctime = true
+}
+
+// RelativeTime displays times as relative ages, such as "3d" or "2mo",
+// instead of absolute timestamps, which is far more readable when looking for
+// what changed recently.
+func RelativeTime(t *TreeFS) {
+	t.relTime = true
+}
+
+// TimeFormat formats the times displayed by ShowMTime, ShowATime and
+// ShowCTime with the time layout layout, e.g. time.RFC3339, rather than the
+// layouts of `tree -D`, like `tree --timefmt`.
+func TimeFormat(layout string) Opt {
+	return func(t *TreeFS) {
+		// Ignore if layout is empty.
+		if layout == "" {
+			return
+		}
+		t.timeLayout = layout
+	}
+}
+
+// WithFileTimes sets the FileTimes used to extract access and change times.
+//
+// By default, times are extracted from the syscall.Stat_t returned by Sys()
+// on the platforms that provide one, such as the fs.FS returned by os.DirFS.
+func WithFileTimes(ft FileTimes) Opt {
+	return func(t *TreeFS) {
+		// Ignore if ft is nil.
+		if ft == nil {
+			return
+		}
+		t.fileTimes = ft
+	}
+}
+
+// Format the time extracted from fi by extract, or a placeholder if it is
+// unknown.
+func (t *TreeFS) formatTime(fi fs.FileInfo, extract func(fs.FileInfo) (time.Time, bool)) string {
+	if fi == nil {
+		return "?"
+	}
+	tm, ok := extract(fi)
+	if !ok {
+		return "?"
+	}
+	if t.relTime {
+		return age(t.now().Sub(tm))
+	}
+	if t.timeLayout != "" {
+		return tm.Format(t.timeLayout)
+	}
+	return formatTime(tm, t.now())
+}
+
+// Extract the modification time of fi.
+func modTime(fi fs.FileInfo) (time.Time, bool) {
+	return fi.ModTime(), true
+}
+
+// Format tm the same way `tree -D` does: recent times include the time of day
+// while times older than six months, or in the future, include the year.
+func formatTime(tm, now time.Time) string {
+	if tm.After(now) || tm.Before(now.AddDate(0, -6, 0)) {
+		return tm.Format("Jan _2  2006")
+	}
+	return tm.Format("Jan _2 15:04")
+}
+
+// Format the duration d as an age in its largest whole unit, e.g. "3d" or
+// "2mo", padded so that ages line up. Negative durations, i.e. times in the
+// future, are prefixed with a "-".
+func age(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+
+	const (
+		day   = 24 * time.Hour
+		month = 30 * day
+		year  = 365 * day
+	)
+	var s string
+	switch {
+	case d < time.Minute:
+		s = fmt.Sprintf("%ds", d/time.Second)
+	case d < time.Hour:
+		s = fmt.Sprintf("%dm", d/time.Minute)
+	case d < day:
+		s = fmt.Sprintf("%dh", d/time.Hour)
+	case d < month:
+		s = fmt.Sprintf("%dd", d/day)
+	case d < year:
+		s = fmt.Sprintf("%dmo", d/month)
+	default:
+		s = fmt.Sprintf("%dy", d/year)
+	}
+	return fmt.Sprintf("%4s", sign+s)
+}
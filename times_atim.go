@@ -0,0 +1,28 @@
+//go:build linux || openbsd || dragonfly || solaris
+
+package treefs
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+// sysTimes extracts times from the syscall.Stat_t returned by Sys().
+type sysTimes struct{}
+
+func (sysTimes) ATime(fi fs.FileInfo) (time.Time, bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(st.Atim.Unix()), true
+}
+
+func (sysTimes) CTime(fi fs.FileInfo) (time.Time, bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(st.Ctim.Unix()), true
+}
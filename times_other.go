@@ -0,0 +1,20 @@
+//go:build !(linux || openbsd || dragonfly || solaris || darwin || freebsd || netbsd)
+
+package treefs
+
+import (
+	"io/fs"
+	"time"
+)
+
+// sysTimes reports every time as unknown on platforms without a supported
+// syscall.Stat_t.
+type sysTimes struct{}
+
+func (sysTimes) ATime(fs.FileInfo) (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (sysTimes) CTime(fs.FileInfo) (time.Time, bool) {
+	return time.Time{}, false
+}
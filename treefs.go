@@ -7,10 +7,20 @@
 package treefs
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
+	"iter"
+	"math"
 	"path"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 )
 
 const (
@@ -48,31 +58,184 @@ func Meta(fsys fs.FS, name string, opts ...Opt) (string, error) {
 	return tfs.Meta(), nil
 }
 
+// Fprint writes the graph, and metadata, of the fs.FS fsys with name name to
+// w, followed by a newline, like Tree. Lines are written as they are
+// rendered, so the output is never held in memory as a single string.
+//
+// If an error is returned, part of the graph may have been written.
+func Fprint(w io.Writer, fsys fs.FS, name string, opts ...Opt) error {
+	return fprint(w, fsys, name, opts, true, true)
+}
+
+// Fgraph writes only the graph of the fs.FS fsys with name name to w,
+// followed by a newline, like Fprint.
+func Fgraph(w io.Writer, fsys fs.FS, name string, opts ...Opt) error {
+	return fprint(w, fsys, name, opts, true, false)
+}
+
+// Fmeta writes only the stringified metadata of the fs.FS fsys with name name
+// to w, followed by a newline.
+func Fmeta(w io.Writer, fsys fs.FS, name string, opts ...Opt) error {
+	return fprint(w, fsys, name, opts, false, true)
+}
+
+// Write the graph, if graph is set, and the reports, if reports is set, of
+// the fs.FS fsys with name name to w.
+func fprint(w io.Writer, fsys fs.FS, name string, opts []Opt, graph, reports bool) error {
+	bw := bufio.NewWriter(w)
+	lines := 0
+	opts = append(opts[:len(opts):len(opts)], OnLine(func(line string) {
+		if graph {
+			bw.WriteString(line)
+			bw.WriteByte('\n')
+		}
+		lines++
+	}))
+	if notTerminal(w) {
+		opts = append(opts, func(t *TreeFS) { t.noColor = true })
+	}
+
+	tfs, err := New(fsys, name, opts...)
+	if err != nil {
+		bw.Flush()
+		return err
+	}
+
+	if reports {
+		sections := tfs.reports()
+		if !graph {
+			sections = []string{tfs.Meta()}
+		}
+		for i, report := range sections {
+			if i > 0 || graph && lines > 0 {
+				bw.WriteByte('\n')
+			}
+			bw.WriteString(report)
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
 // New returns a TreeFS whose stringer interface implementation returns the
 // graph for the fs.FS fsys and name name, similar to the `tree` command.
 //
 // It makes use of fs.ReadDir to walk fsys.
 func New(fsys fs.FS, name string, opts ...Opt) (tfs TreeFS, err error) {
-	tfs = TreeFS{
-		fsys: fsys,
-		tree: []string{name},
-	}
+	tfs = defaults()
 	for _, opt := range opts {
 		opt(&tfs)
 	}
 
+	err = build(&tfs, fsys, name)
+	return
+}
+
+// Return a TreeFS with the defaults that Opts override.
+func defaults() TreeFS {
+	return TreeFS{
+		fileTimes: sysTimes{},
+		maxSize:   math.MaxInt64,
+		now:       time.Now,
+		style:     DefaultStyle,
+	}
+}
+
+// Walk the fs.FS fsys with name name into tfs, whose Opts have already been
+// applied.
+func build(tfs *TreeFS, fsys fs.FS, name string) (err error) {
 	// Since the filesystem fsys does not contain any file within it by the
 	// name "../*", we substitute name for "." if a directory from any level
 	// above CWD is provided.
 	// Also, if name is "." (whether provided or due to the fact that it
 	// contains a "../") pathPrefix is set to name (before the overwrite) for
 	// use in case the FullPathPrefix Opt was applied to tfs.
+	p := name
 	if strings.Contains(name, "../") || name == "." {
 		tfs.pathPrefix = name
-		name = "."
+		p = "."
+	}
+
+	return buildAt(tfs, fsys, name, p)
+}
+
+// Build the TreeFS tfs from the entry with path p of fsys, graphed as name.
+func buildAt(tfs *TreeFS, fsys fs.FS, name, p string) (err error) {
+	if tfs.optErr != nil {
+		return tfs.optErr
+	}
+	tfs.fsys = fsys
+	if tfs.stream != nil {
+		tfs.unstreamable()
+	}
+	if noColor() {
+		tfs.noColor = true
+	}
+	root := &Node{Name: name, Path: p, IsDir: true}
+
+	if !fs.ValidPath(p) {
+		return &fs.PathError{Op: "open", Path: p, Err: ErrInvalidName}
 	}
 
-	err = treeFSWithPrefix(&tfs, name, "", 0)
+	fi, serr := fs.Stat(fsys, p)
+	switch {
+	case errors.Is(serr, fs.ErrNotExist):
+		return &fs.PathError{Op: "open", Path: p, Err: ErrNotExist}
+	case serr != nil:
+		// Let the walk report why name can't be read.
+	case !fi.Mode().IsRegular() && !fi.IsDir():
+		return &fs.PathError{Op: "open", Path: p, Err: ErrNotDir}
+	}
+
+	// The root is only output once it is known to be graphable, so that
+	// nothing is written by Fprint, OnLine or Stream for an invalid one.
+	start := len(tfs.tree)
+	tfs.emit(Line{Name: name, Comment: tfs.comment(p)})
+	if tfs.streamErr != nil {
+		return tfs.streamErr
+	}
+
+	if serr == nil && fi.Mode().IsRegular() {
+		// Like `tree`, a file is graphed on its own rather than failing to
+		// be read as a directory.
+		root.IsDir = false
+		tfs.NFiles = 1
+		if tfs.sumSizes {
+			tfs.nbytes = fi.Size()
+		}
+		tfs.roots = append(tfs.roots, root)
+		if err = tfs.writeManifest(root); err != nil {
+			return
+		}
+		return tfs.event(File, root, nil)
+	}
+
+	if err = tfs.walk(root); err != nil {
+		return
+	}
+	if tfs.du {
+		tfs.totalSize += tfs.diskUsage(root)
+	}
+	if tfs.countAll {
+		tfs.trim(root)
+	}
+
+	tfs.roots = append(tfs.roots, root)
+	entries := tfs.top(root)
+	if tfs.countVisible {
+		tfs.NDirs, tfs.NFiles, tfs.NIrregular, tfs.NSymlinks = 0, 0, 0, 0
+	}
+	tfs.render(entries, "")
+	alignComments(tfs.tree[start:])
+
+	// Annotations are read while rendering, so this is the first point at
+	// which every failure is known.
+	if len(tfs.errs) > 0 {
+		return &StrictError{Errs: tfs.errs}
+	}
+	if tfs.truncated {
+		return tfs.truncate()
+	}
 	return
 }
 
@@ -96,19 +259,111 @@ func NewMulti(args ...Arg) (tfs TreeFS, err error) {
 		if tfs2, err = New(arg.Fsys, arg.Name, arg.Opts...); err != nil {
 			return
 		}
+		tfs.merge(tfs2)
+	}
+
+	return
+}
 
-		tfs.tree = append(tfs.tree, tfs2.tree...)
-		tfs.NDirs += tfs2.NDirs
-		tfs.NFiles += tfs2.NFiles
+// NewMultiPartial is like NewMulti, but rather than failing on the first Arg
+// that can't be graphed, it aggregates those that can.
+//
+// The error of each Arg, or nil if it was graphed, is returned in errs, in
+// the order of args, and their errors.Join as err.
+func NewMultiPartial(args ...Arg) (tfs TreeFS, errs []error, err error) {
+	errs = make([]error, len(args))
+	for i, arg := range args {
+		tfs2, aerr := New(arg.Fsys, arg.Name, arg.Opts...)
+		if aerr != nil {
+			errs[i] = aerr
+			continue
+		}
+		tfs.merge(tfs2)
 	}
 
+	return tfs, errs, errors.Join(errs...)
+}
+
+// NewMultiContext is like NewMulti, but constructs the TreeFS of each Arg
+// concurrently, since the fs.FS of different Args are usually independent.
+//
+// If ctx is done before every Arg is graphed, the walks stop and ctx.Err()
+// is returned. Otherwise, the first error to occur stops the other walks and
+// is returned.
+func NewMultiContext(ctx context.Context, args ...Arg) (tfs TreeFS, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg   sync.WaitGroup
+		once sync.Once
+	)
+	trees := make([]TreeFS, len(args))
+	for i, arg := range args {
+		wg.Add(1)
+		go func(i int, arg Arg) {
+			defer wg.Done()
+
+			trees[i] = defaults()
+			for _, opt := range arg.Opts {
+				opt(&trees[i])
+			}
+			trees[i].ctx = ctx
+			if berr := build(&trees[i], arg.Fsys, arg.Name); berr != nil {
+				once.Do(func() {
+					err = berr
+					cancel()
+				})
+			}
+		}(i, arg)
+	}
+	wg.Wait()
+
+	if err != nil {
+		return TreeFS{}, err
+	}
+	for _, tfs2 := range trees {
+		tfs.merge(tfs2)
+	}
 	return
 }
 
+// Aggregate the graph, and metadata, of tfs2 into t.
+func (t *TreeFS) merge(tfs2 TreeFS) {
+	if tfs2.du {
+		t.du, t.sizeFmt = true, tfs2.sizeFmt
+		t.totalSize += tfs2.totalSize
+	}
+	if t.style == (Style{}) {
+		t.style = tfs2.style
+	}
+	if tfs2.noReport {
+		t.noReport = true
+	}
+	if t.metaFormat == nil {
+		t.metaFormat = tfs2.metaFormat
+	}
+	t.tree = append(t.tree, tfs2.tree...)
+	t.roots = append(t.roots, tfs2.roots...)
+	t.NDirs += tfs2.NDirs
+	t.NFiles += tfs2.NFiles
+	t.NIrregular += tfs2.NIrregular
+	t.NSymlinks += tfs2.NSymlinks
+	t.NScanned += tfs2.NScanned
+	if tfs2.sumSizes {
+		t.sumSizes = true
+		t.nbytes += tfs2.nbytes
+	}
+	if tfs2.maxDepth > t.maxDepth {
+		t.maxDepth = tfs2.maxDepth
+	}
+}
+
 // TreeFS contains the required information to construct a graph for an fs.FS.
 type TreeFS struct {
-	fsys fs.FS
-	tree []string
+	fsys  fs.FS
+	tree  []Line
+	roots []*Node // the walked tree of each fs.FS
 	// The path prefix for cases where the fs.FS has a name that contains "."
 	// or "../".
 	//
@@ -118,12 +373,147 @@ type TreeFS struct {
 
 	NDirs  int // the number of directories that exist within an fs.FS
 	NFiles int // the number of files that exist within an fs.Fs
+	// The number of irregular files (fs.ModeIrregular) that exist within an
+	// fs.FS, which are not counted in NFiles.
+	NIrregular int
+	// The number of symbolic links that exist within an fs.FS, which are
+	// not counted in NFiles unless followed to a directory by FollowSymlinks.
+	NSymlinks int
+	// The number of entries read from an fs.FS, including those that are
+	// neither displayed nor counted, e.g. because of filters.
+	NScanned int
+
+	// The maximum depth of the entries walked, 0 being the root.
+	maxDepth int
+
+	// Errors encountered while walking fsys in strict mode.
+	errs []error
 
 	// Opts ...
 	hidden         bool // allow hidden directories and entries
 	dirOnly        bool // list directories only
 	fullPathPrefix bool // includes the full path prefix for each file
 	level          int  // max display depth of the directory tree
+	countAll       bool // walk beyond the max display depth for the metadata
+	countVisible   bool // count only the rendered entries in the metadata
+	minDepth       int  // min display depth of the directory tree
+	strict         bool // collect every ReadDir and Info failure
+
+	labels map[*Node]string // names displayed instead of Node names
+
+	foldIdentical   bool // fold structurally identical sibling directories
+	dedupeIdentical bool // summarize structurally identical sibling directories
+	elideWidth      int  // max width of names before they're elided
+	deepestN        int  // number of deepest paths to report
+	largestN        int  // number of largest files to report
+	previewBytes    int  // bytes read to preview the first line of files
+	brokenLinks     bool // annotate symlinks whose target doesn't resolve
+	classify        bool // append type indicators to names
+	quoteNames      bool // wrap names in double quotes
+	noReport        bool // omit the metadata from String
+	dirCounts       bool // display the number of entries below directories
+
+	metaFormat func(ndirs, nfiles int) string // formats the metadata, if set
+
+	followJunctions bool // walk junctions and other reparse points
+
+	followSymlinks bool           // walk symlinks to directories
+	visiting       []string       // resolved paths of the directories being walked
+	loops          map[*Node]bool // followed symlinks that would recurse
+
+	opaque    map[string]bool // names of directories that aren't walked
+	collapsed map[string]bool // names of directories whose entries aren't rendered
+	summaries map[*Node]int   // number of entries of opaque directories
+
+	pageSize    int  // max number of entries displayed per directory, if set
+	moreEntries bool // count the entries left out by pageSize as entries
+
+	fileLimit int           // max number of entries of walked directories, if set
+	overLimit map[*Node]int // number of entries of directories exceeding it
+
+	unsorted   bool                 // list entries in the order of the fs.FS
+	sortNames  bool                 // sort entries by name regardless of the fs.FS
+	dirsFirst  bool                 // list directories before files
+	filesFirst bool                 // list files before directories
+	cmp        func(a, b *Node) int // orders the entries of each directory, if set
+
+	minSize int64 // min size of displayed files
+	maxSize int64 // max size of displayed files
+
+	newerThan time.Time // displayed files are modified after, if set
+	olderThan time.Time // displayed files are modified before, if set
+
+	fuzzy  string   // pattern displayed paths fuzzily match, if set
+	match  []string // patterns displayed file names match, if set
+	ignore []string // patterns hidden entry names match, if set
+
+	ignoreFile string // name of the files Ignore patterns are read from
+
+	ignoreMatchers []IgnoreMatcher // decide which entries are hidden
+	optErr         error           // the failures of Opts, returned by New
+
+	exts         map[string]bool // extensions of displayed files, if set
+	excludedExts map[string]bool // extensions of hidden files
+
+	contentRe    *regexp.Regexp // pattern the contents of displayed files match, if set
+	contentLimit int64          // max bytes of each file searched for contentRe
+
+	ignoreCase bool // match patterns regardless of case
+	matchDirs  bool // match directory names against patterns too
+
+	comments map[string]string                       // comments displayed after entries by path
+	decorate func(path string, d fs.DirEntry) string // returns suffixes of entries, if set
+
+	prune bool // omit directories left empty by file filters
+
+	onEvent func(Event) error // called for every traversal event
+	onLine  func(string)      // called for every graph line, if set
+
+	stream    io.Writer // where the graph is written while walking, if set
+	streamErr error     // the failure to write to stream, if any
+	prefixes  []string  // the prefixes of the directories being streamed
+
+	manifest  io.Writer     // where the Manifest is written, if set
+	jsonLines *json.Encoder // where JSONLines are written, if set
+
+	ctx context.Context // stops the walk once done, if set
+
+	maxOutputBytes int  // max size of the graph, in bytes
+	outputBytes    int  // size of the graph so far, in bytes
+	outputLines    int  // number of lines of the graph so far
+	maxNodes       int  // max number of entries of the graph
+	nodes          int  // number of entries of the graph so far
+	truncated      bool // whether the graph exceeded maxOutputBytes or maxNodes
+	nodeLimited    bool // whether the graph exceeded maxNodes
+
+	connectorPalette []string  // SGR parameters cycled through by depth
+	namePalette      []string  // SGR parameters cycled through by depth
+	lsColors         *lsColors // colors of names by type and extension
+	noColor          bool      // disable colors, e.g. due to NO_COLOR
+	style            Style     // the glyphs the graph is drawn with
+
+	// Returns the escape sequences wrapping the name of each entry, if set.
+	colorFunc func(path string, d fs.DirEntry) (prefix, suffix string)
+
+	owner      bool              // display the user owning entries
+	group      bool              // display the group owning entries
+	userNames  map[uint32]string // memoized user names by ID
+	groupNames map[uint32]string // memoized group names by ID
+
+	sizeFmt    func(int64) string // formats entry sizes, if displayed
+	du         bool               // display accumulated directory sizes
+	totalSize  int64              // accumulated size of every root
+	sumSizes   bool               // display the total size of files
+	nbytes     int64              // total size of the files walked
+	mtime      bool               // display last modification times
+	atime      bool               // display last access times
+	ctime      bool               // display last status change times
+	relTime    bool               // display times as relative ages
+	timeLayout string             // layout of displayed times, if set
+	now        func() time.Time   // the time relative ages are measured from
+	gitStatus  GitStatusFunc      // reports the git status of entries
+	mimeTypes  bool               // display the MIME type of entries
+	fileTimes  FileTimes          // extracts access and change times
 }
 
 // String implements the stringer interface for TreeFS.
@@ -131,23 +521,197 @@ type TreeFS struct {
 // It returns the stringified graph of the TreeFS t with metadata at the
 // bottom, similar to the `tree` command.
 func (t TreeFS) String() string {
-	return t.Graph() + "\n\n" + t.Meta()
+	sections := t.reports()
+	if graph := t.Graph(); graph != "" {
+		sections = append([]string{graph}, sections...)
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// WriteTo implements the io.WriterTo interface for TreeFS.
+//
+// It writes the String of t, followed by a newline, to w line by line, so
+// that large graphs are never held in memory as a single string.
+func (t TreeFS) WriteTo(w io.Writer) (n int64, err error) {
+	cw := &countWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	for _, line := range t.tree {
+		bw.WriteString(line.String())
+		bw.WriteByte('\n')
+	}
+	for i, report := range t.reports() {
+		if i > 0 || len(t.tree) > 0 {
+			bw.WriteByte('\n')
+		}
+		bw.WriteString(report)
+		bw.WriteByte('\n')
+	}
+	// A bufio.Writer keeps the first error of w, returning it from Flush.
+	err = bw.Flush()
+	return cw.n, err
+}
+
+// Return the sections following the graph of t in its String.
+func (t TreeFS) reports() []string {
+	var sections []string
+	if !t.noReport {
+		sections = append(sections, t.Meta())
+	}
+	if t.deepestN > 0 {
+		sections = append(sections, t.deepestReport())
+	}
+	if t.largestN > 0 {
+		sections = append(sections, t.largestReport())
+	}
+	return sections
+}
+
+// countWriter is an io.Writer counting the bytes written to w.
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
 }
 
 // Graph returns the stringified graph of the TreeFS t without any metadata.
 func (t TreeFS) Graph() string {
-	return strings.Join(t.tree, "\n")
+	var b strings.Builder
+	for i, line := range t.tree {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(line.String())
+	}
+	return b.String()
+}
+
+// Len returns the number of lines of the graph of the TreeFS t.
+//
+// It is 0 if the OnLine or Stream Opt was applied, since the lines are not
+// kept.
+func (t TreeFS) Len() int {
+	return len(t.tree)
+}
+
+// NumEntries returns the number of entries walked, excluding the root of
+// each fs.FS.
+func (t TreeFS) NumEntries() int {
+	var n int
+	for _, root := range t.roots {
+		n += root.ndirs + root.nfiles
+	}
+	return n
+}
+
+// Root returns the Node of the root of the fs.FS walked into t, with its
+// entries as Children, in display order, or nil if nothing was walked. For
+// an aggregate TreeFS, it is the first root.
+//
+// The Info of every Node but the root is loaded, if it wasn't already.
+func (t TreeFS) Root() *Node {
+	if roots := t.Roots(); len(roots) > 0 {
+		return roots[0]
+	}
+	return nil
+}
+
+// Roots returns the Node of the root of each fs.FS walked into t, like Root.
+func (t TreeFS) Roots() []*Node {
+	for _, root := range t.roots {
+		root.load()
+	}
+	return t.roots
+}
+
+// Walk calls fn for every Node walked into t, roots included, in display
+// order, e.g. to generate a sitemap without reading the fs.FS again. depth is
+// the depth of the Node, 0 being a root.
+//
+// Like with fs.WalkDir, if fn returns fs.SkipDir for a directory, its entries
+// are skipped, and for a file, the remaining entries of its directory are.
+// If fn returns fs.SkipAll, the walk stops and Walk returns nil. Any other
+// error stops the walk and is returned.
+//
+// Unlike with Root, the Info of the Nodes isn't loaded, since it can be
+// expensive on remote filesystems; call Stat for the Nodes that need it.
+func (t TreeFS) Walk(fn func(n *Node, depth int) error) error {
+	for _, root := range t.roots {
+		if err := walkNode(root, fn); err != nil && err != fs.SkipDir {
+			if err == fs.SkipAll {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// All returns an iterator over the path and Node of every Node walked into t,
+// roots included, in display order, like Walk:
+//
+//	for p, n := range tfs.All() {
+//		fmt.Println(p, n.IsDir)
+//	}
+func (t TreeFS) All() iter.Seq2[string, *Node] {
+	return func(yield func(string, *Node) bool) {
+		_ = t.Walk(func(n *Node, _ int) error {
+			if !yield(n.Path, n) {
+				return fs.SkipAll
+			}
+			return nil
+		})
+	}
+}
+
+// Call fn for the Node n and the Nodes below it, in pre-order.
+func walkNode(n *Node, fn func(n *Node, depth int) error) error {
+	if err := fn(n, n.depth); err != nil || !n.IsDir {
+		return err
+	}
+	for _, child := range n.Children {
+		if err := walkNode(child, fn); err != nil {
+			if err == fs.SkipDir {
+				if child.IsDir {
+					continue
+				}
+				// Skip the remaining entries of n.
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Depth returns the maximum depth reached while walking, where the entries of
+// the root are at depth 1, e.g. for policy checks limiting nesting.
+func (t TreeFS) Depth() int {
+	return t.maxDepth
 }
 
 // Meta returns the stringified metadata for the TreeFS t.
 func (t TreeFS) Meta() string {
+	if t.metaFormat != nil {
+		return t.metaFormat(t.NDirs, t.NFiles)
+	}
+
 	dirs := "directories"
 	if t.NDirs == 1 {
 		dirs = "directory"
 	}
 
+	var usage string
+	if t.du {
+		usage = t.usage()
+	}
+
 	if t.dirOnly {
-		return fmt.Sprintf("%d %s", t.NDirs, dirs)
+		return fmt.Sprintf("%s%d %s", usage, t.NDirs, dirs)
 	}
 
 	files := "files"
@@ -155,7 +719,25 @@ func (t TreeFS) Meta() string {
 		files = "file"
 	}
 
-	return fmt.Sprintf("%d %s, %d %s", t.NDirs, dirs, t.NFiles, files)
+	meta := fmt.Sprintf("%s%d %s, %d %s", usage, t.NDirs, dirs, t.NFiles, files)
+	if t.NIrregular > 0 {
+		irregular := "irregular files"
+		if t.NIrregular == 1 {
+			irregular = "irregular file"
+		}
+		meta += fmt.Sprintf(", %d %s", t.NIrregular, irregular)
+	}
+	if t.NSymlinks > 0 {
+		links := "symbolic links"
+		if t.NSymlinks == 1 {
+			links = "symbolic link"
+		}
+		meta += fmt.Sprintf(", %d %s", t.NSymlinks, links)
+	}
+	if t.sumSizes {
+		meta += ", " + t.totalBytes()
+	}
+	return meta
 }
 
 // Filter the displaying of entries based on t's internal state.
@@ -172,100 +754,372 @@ func (t TreeFS) allow(entry fs.DirEntry) bool {
 		return false
 	}
 
+	// Skip if the entry matches an Ignore pattern.
+	if len(t.ignore) > 0 && matchAny(t.ignore, name, t.ignoreCase) {
+		return false
+	}
+
 	return true
 }
 
-// Append the prefix, connector, name combo to the tree t.
-func (t *TreeFS) append(prefix, connector, dirPath, name string) {
-	if !t.fullPathPrefix {
-		t.tree = append(t.tree, fmt.Sprintf("%s%s %s", prefix, connector, name))
-		return
+// Record the failure err of operation op on path p.
+//
+// Errors that do not already carry a path are wrapped in an *fs.PathError so
+// that a StrictError can always report which path failed.
+func (t *TreeFS) fail(op, p string, err error) {
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) {
+		err = &fs.PathError{Op: op, Path: p, Err: err}
 	}
+	t.errs = append(t.errs, err)
+}
 
-	if t.pathPrefix != "" {
-		t.tree = append(t.tree, fmt.Sprintf("%s%s %s/%s", prefix, connector, t.pathPrefix, path.Join(dirPath, name)))
+// Append the prefix, connector, name combo of the Node n to the tree t,
+// followed by note if it is not empty.
+func (t *TreeFS) append(prefix, connector string, n *Node, note string) {
+	if t.nodeLimitReached() {
 		return
 	}
+	t.nodes++
+
+	name := n.Name
+	if label, ok := t.labels[n]; ok {
+		name = label
+	}
+	if t.fullPathPrefix {
+		name = n.Path
+		if t.pathPrefix != "" {
+			name = t.pathPrefix + "/" + name
+		}
+	}
+	if t.elideWidth > 0 {
+		name = elidePath(name, t.elideWidth)
+	}
+	if t.quoteNames {
+		name = `"` + strings.ReplaceAll(name, `"`, `\"`) + `"`
+	}
+
+	line := Line{
+		Prefix:    prefix,
+		Connector: connector,
+		Info:      t.info(n),
+		Name:      t.paintName(name, n),
+	}
+	if t.classify {
+		line.Name += t.indicator(n)
+	}
+	if n.Target != "" {
+		target := n.Target
+		if t.quoteNames {
+			target = `"` + strings.ReplaceAll(target, `"`, `\"`) + `"`
+		}
+		line.Annotation += " -> " + target
+	}
+	if t.loops[n] {
+		line.Annotation += " [recursive, not followed]"
+	}
+	if t.brokenLinks && t.broken(n) {
+		line.Annotation += " [broken]"
+	}
+	if t.junction(n) {
+		line.Annotation += " [junction]"
+	} else if n.entry != nil && n.entry.Type()&fs.ModeIrregular != 0 {
+		line.Annotation += " [irregular]"
+	}
+	if count, ok := t.summaries[n]; ok {
+		line.Annotation += " [" + plural(count, "entry", "entries") + "]"
+	}
+	if count, ok := t.overLimit[n]; ok {
+		line.Annotation += " [" + plural(count, "entry", "entries") + " exceeds filelimit]"
+	}
+	if t.dirCounts {
+		line.Annotation += t.dirCount(n)
+	}
+	if note != "" {
+		line.Annotation += " " + note
+	}
+	if preview := t.preview(n); preview != "" {
+		line.Annotation += "  " + preview
+	}
+	if t.decorate != nil && n.entry != nil {
+		if suffix := t.decorate(n.Path, n.entry); suffix != "" {
+			line.Annotation += " " + suffix
+		}
+	}
+	line.Comment = t.comment(n.Path)
 
-	t.tree = append(t.tree, fmt.Sprintf("%s%s %s", prefix, connector, path.Join(dirPath, name)))
+	t.emit(line)
+	if t.countVisible && !t.truncated {
+		t.count(n)
+	}
 }
 
-// Recursively generate the tree of the TreeFS treefs.
-//
-// XXX(algebra8):
-//	This implementation for recursively creating a filesystem tree is inspired
-//	by the Python tutorial "Build a Python Directory Tree Generator for the
-//	Command Line" at realpython.com
-//	(https://realpython.com/directory-tree-generator-python/).
-//
-//	Credits to the author, Leodanis Pozo Ramos.
-func treeFSWithPrefix(tfs *TreeFS, name, prefix string, lvl int) (err error) {
-	// Return if max level has been set and reached.
-	if tfs.level > 0 && lvl == tfs.level {
+// Return the bracketed file information displayed before n's name, or an
+// empty string if no Opt requiring it was applied.
+func (t *TreeFS) info(n *Node) string {
+	if !t.owner && !t.group && t.sizeFmt == nil && !t.mtime && !t.atime && !t.ctime && t.gitStatus == nil && !t.mimeTypes {
+		return ""
+	}
+
+	var fields []string
+	fi, _ := n.info()
+
+	if t.owner {
+		fields = append(fields, t.formatOwner(fi))
+	}
+	if t.group {
+		fields = append(fields, t.formatGroup(fi))
+	}
+
+	if t.sizeFmt != nil {
+		size := "?"
+		switch {
+		case t.du && n.IsDir:
+			size = t.sizeFmt(n.du)
+		case fi != nil:
+			size = t.sizeFmt(fi.Size())
+		}
+		fields = append(fields, size)
+	}
+	if t.mtime {
+		fields = append(fields, t.formatTime(fi, modTime))
+	}
+	if t.atime {
+		fields = append(fields, t.formatTime(fi, t.fileTimes.ATime))
+	}
+	if t.ctime {
+		fields = append(fields, t.formatTime(fi, t.fileTimes.CTime))
+	}
+	if t.gitStatus != nil {
+		fields = append(fields, t.formatGitStatus(n.Path))
+	}
+	if t.mimeTypes {
+		fields = append(fields, t.mimeType(n))
+	}
+
+	if len(fields) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(fields, " ") + "]"
+}
+
+// Recursively read the entries of the directory Node n into its Children.
+func (t *TreeFS) walk(n *Node) (err error) {
+	if err = t.event(EnterDir, n, nil); err != nil {
+		return
+	}
+	t.visiting = append(t.visiting, n.realPath())
+	defer func() { t.visiting = t.visiting[:len(t.visiting)-1] }()
+	if err = t.readDir(n); err != nil {
+		return
+	}
+	return t.event(LeaveDir, n, nil)
+}
+
+// Read the entries of the directory Node n into its Children, walking each
+// of its subdirectories.
+func (t *TreeFS) readDir(n *Node) (err error) {
+	// Return if max level has been set and reached, unless every entry is
+	// counted.
+	if t.level > 0 && n.depth == t.level && !t.countAll {
 		return
 	}
 
 	var entries []fs.DirEntry
-	if entries, err = fs.ReadDir(tfs.fsys, name); err != nil {
+	if entries, err = t.readEntries(n.Path); err != nil {
+		if eerr := t.event(Error, n, err); eerr != nil {
+			return eerr
+		}
+		if !t.strict {
+			return
+		}
+		// Keep walking whatever could be read so that every failing path
+		// is reported.
+		t.fail("readdir", n.Path, err)
+		err = nil
+	}
+
+	t.NScanned += len(entries)
+	if t.exceedsFileLimit(n, entries) {
 		return
 	}
-	numEntries := len(entries)
 
-	for i, entry := range entries {
-		if !tfs.allow(entry) {
+	n.ignore = t.ignorePatterns(n)
+	for _, entry := range entries {
+		if !t.allow(entry) || matchAny(n.ignore, entry.Name(), t.ignoreCase) {
+			continue
+		}
+		if t.ignored(path.Join(n.Path, entry.Name()), entry) {
 			continue
 		}
 
-		connector := teeConnector
-		if i == numEntries-1 {
-			connector = elbowConnector
-		}
-
-		if entry.IsDir() {
-			tfs.NDirs++
-			// XXX(algebra8):
-			// 	One benefit to using addDir as a separate function is the
-			// 	handling of prefix state.
-			// 	The outer prefix won't be affected by the state change in
-			// 	addDir so recursion handles any necessary prefix trimming.
-			if err = addDir(tfs, addDirArgs{
-				path:      name,
-				name:      entry.Name(),
-				idx:       i,
-				numFiles:  numEntries,
-				prefix:    prefix,
-				connector: connector,
-				lvl:       lvl,
-			}); err != nil {
+		child := &Node{
+			Name:   entry.Name(),
+			Path:   path.Join(n.Path, entry.Name()),
+			IsDir:  entry.IsDir(),
+			entry:  entry,
+			depth:  n.depth + 1,
+			ignore: n.ignore,
+		}
+		if n.real != "" {
+			child.real = path.Join(n.real, child.Name)
+		}
+		if entry.Type()&fs.ModeSymlink != 0 {
+			child.Target = t.readLink(child)
+			if t.followSymlinks {
+				t.follow(child)
+			}
+		}
+		t.matchDir(n, child)
+
+		if t.strict {
+			if _, ierr := child.info(); ierr != nil {
+				t.fail("stat", child.Path, ierr)
+				if err = t.event(Error, child, ierr); err != nil {
+					return
+				}
+			}
+		}
+
+		if !child.IsDir {
+			if !t.allowFile(child) {
+				continue
+			}
+			switch {
+			case entry.Type()&fs.ModeIrregular != 0:
+				t.NIrregular++
+			case entry.Type()&fs.ModeSymlink != 0:
+				t.NSymlinks++
+			default:
+				t.NFiles++
+			}
+			n.nfiles++
+			if t.sumSizes {
+				t.sumSize(child)
+			}
+			t.add(n, child)
+			if err = t.writeManifest(child); err != nil {
+				return
+			}
+			if err = t.event(File, child, nil); err != nil {
 				return
 			}
 			continue
 		}
 
-		tfs.NFiles++
-		tfs.append(prefix, connector, name, entry.Name())
+		switch {
+		case t.opaque[child.Name]:
+			t.summarize(child)
+		case t.collapsed[child.Name]:
+			if err = t.walk(child); err != nil {
+				return
+			}
+			if t.pruned(child) {
+				continue
+			}
+			t.collapse(child)
+		case t.loops[child]:
+			// Recursive symlinks are listed, but not walked.
+		case t.followJunctions || !t.junction(child):
+			// Junctions are listed, but not walked, since they may form
+			// cycles, unless FollowJunctions was applied.
+			if t.stream != nil {
+				// Walked once its line is written, see streamDir.
+				child.deferred = true
+				break
+			}
+			if err = t.walk(child); err != nil {
+				return
+			}
+			if t.pruned(child) {
+				continue
+			}
+		}
+		t.NDirs++
+		n.ndirs += 1 + child.ndirs
+		n.nfiles += child.nfiles
+		t.add(n, child)
 	}
 
+	t.sort(n.Children)
+	if t.stream != nil {
+		return t.streamDir(n)
+	}
 	return
 }
 
-// Container for addDir args.
-type addDirArgs struct {
-	path, name         string
-	idx, numFiles, lvl int
-	prefix, connector  string
+// Add the Node child to the Children of the directory Node n.
+func (t *TreeFS) add(n, child *Node) {
+	n.Children = append(n.Children, child)
+	if child.depth > t.maxDepth {
+		t.maxDepth = child.depth
+	}
 }
 
-func addDir(tfs *TreeFS, args addDirArgs) error {
-	tfs.append(args.prefix, args.connector, args.path, args.name)
+// Recursively render the entries, and their children, into the tree t, with
+// each line preceded by prefix.
+//
+// XXX(algebra8):
+//	This implementation for recursively creating a filesystem tree is inspired
+//	by the Python tutorial "Build a Python Directory Tree Generator for the
+//	Command Line" at realpython.com
+//	(https://realpython.com/directory-tree-generator-python/).
+//
+//	Credits to the author, Leodanis Pozo Ramos.
+func (t *TreeFS) render(entries []*Node, prefix string) {
+	var folds map[string]string
+	if t.foldIdentical {
+		folds = make(map[string]string)
+	}
+
+	var dupes map[*Node]int
+	if t.dedupeIdentical {
+		entries, dupes = dedupe(entries)
+	}
 
-	if args.idx != args.numFiles-1 {
-		args.prefix += pipePrefix
-	} else {
-		args.prefix += spacePrefix
+	more := 0
+	if t.pageSize > 0 && len(entries) > t.pageSize {
+		more = len(entries) - t.pageSize
+		entries = entries[:t.pageSize]
 	}
 
-	return treeFSWithPrefix(tfs, path.Join(args.path, args.name), args.prefix, args.lvl+1)
+	for i, child := range entries {
+		if t.truncated {
+			return
+		}
+
+		connector, childPrefix := t.style.Tee, prefix+t.paintConnector(t.style.Pipe, child.depth)
+		if i == len(entries)-1 && more == 0 && dupes[child] == 0 {
+			connector, childPrefix = t.style.Elbow, prefix+t.style.Space
+		}
+		connector = t.paintConnector(connector, child.depth)
+
+		if !child.IsDir {
+			t.append(prefix, connector, child, "")
+			continue
+		}
+
+		if folds != nil && len(child.Children) > 0 {
+			sig := child.signature()
+			if first, ok := folds[sig]; ok {
+				t.append(prefix, connector, child, "[same structure as "+first+"]")
+				continue
+			}
+			folds[sig] = child.Name
+		}
+
+		t.append(prefix, connector, child, "")
+		if !t.collapsed[child.Name] {
+			t.render(child.Children, childPrefix)
+		}
+		if n := dupes[child]; n > 0 && !t.truncated {
+			t.identical(prefix, child.depth, n, i == len(entries)-1 && more == 0)
+		}
+	}
+
+	if more > 0 && !t.truncated {
+		t.more(prefix, entries[0].depth, more)
+	}
 }
 
 // Opt defines an optional argument for generating an fs.FS's tree.
@@ -286,6 +1140,27 @@ func FullPathPrefix(t *TreeFS) {
 	t.fullPathPrefix = true
 }
 
+// NoReport omits the metadata, e.g. "2 directories, 5 files", from the String
+// of the TreeFS, like `tree -n`. It is still returned by Meta.
+func NoReport(t *TreeFS) {
+	t.noReport = true
+}
+
+// QuoteNames wraps the name of each entry in double quotes, escaping any
+// double quotes within it, like `tree -Q`, so that names with spaces or odd
+// characters are unambiguous.
+func QuoteNames(t *TreeFS) {
+	t.quoteNames = true
+}
+
+// Strict makes New fail if any entry of the fs.FS cannot be read.
+//
+// Rather than stopping at the first failure, the whole fs.FS is walked and
+// every ReadDir or Info failure is collected into a *StrictError.
+func Strict(t *TreeFS) {
+	t.strict = true
+}
+
 // Level sets the max display depth of the directory tree.
 func Level(lvl int) Opt {
 	return func(tfs *TreeFS) {
@@ -296,3 +1171,87 @@ func Level(lvl int) Opt {
 		tfs.level = lvl
 	}
 }
+
+// CountAll walks the whole tree, even beyond the max depth set by Level, so
+// that the metadata counts every entry, like `tree -L` does. Only the entries
+// up to the max depth are displayed.
+func CountAll(t *TreeFS) {
+	t.countAll = true
+}
+
+// CountVisibleOnly counts exactly the entries that are rendered in the
+// metadata, leaving out those that are walked but not displayed, e.g. because
+// of Paginate, Collapse or CountAll. NScanned still counts every entry read.
+func CountVisibleOnly(t *TreeFS) {
+	t.countVisible = true
+}
+
+// Count the rendered entry Node n in the metadata of t.
+func (t *TreeFS) count(n *Node) {
+	switch mode := n.mode(); {
+	case n.IsDir:
+		t.NDirs++
+	case mode&fs.ModeIrregular != 0:
+		t.NIrregular++
+	case mode&fs.ModeSymlink != 0:
+		t.NSymlinks++
+	default:
+		t.NFiles++
+	}
+}
+
+// Errors returned by New, wrapped in an *fs.PathError, when the name of the
+// root can't be graphed.
+var (
+	// ErrInvalidName means the name is not a valid path according to
+	// fs.ValidPath. errors.Is(ErrInvalidName, fs.ErrInvalid) reports true.
+	ErrInvalidName error = &rootError{"invalid name", fs.ErrInvalid}
+	// ErrNotDir means the root is neither a directory nor a regular file.
+	ErrNotDir error = &rootError{"not a directory", nil}
+	// ErrNotExist means the root does not exist. errors.Is(ErrNotExist,
+	// fs.ErrNotExist) reports true.
+	ErrNotExist error = &rootError{"no such file or directory", fs.ErrNotExist}
+)
+
+// rootError is the type of the errors for roots that can't be graphed.
+type rootError struct {
+	msg string
+	err error // the more general error, if any
+}
+
+func (e *rootError) Error() string { return e.msg }
+func (e *rootError) Unwrap() error { return e.err }
+
+// StrictError is returned by New when the Strict Opt is applied and at least
+// one entry of the fs.FS could not be read.
+type StrictError struct {
+	Errs []error // every failure, in walk order, each an *fs.PathError
+}
+
+// Error implements the error interface for StrictError.
+func (e *StrictError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "treefs: %d unreadable path(s):", len(e.Errs))
+	for _, err := range e.Errs {
+		b.WriteString("\n\t")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the errors wrapped by e.
+func (e *StrictError) Unwrap() []error {
+	return e.Errs
+}
+
+// Paths returns every path that could not be read.
+func (e *StrictError) Paths() []string {
+	paths := make([]string, 0, len(e.Errs))
+	for _, err := range e.Errs {
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			paths = append(paths, pathErr.Path)
+		}
+	}
+	return paths
+}
@@ -2,14 +2,22 @@ package treefs
 
 import (
 	"bytes"
+	"context"
 	"embed"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"os/exec"
+	"path"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 	"testing/fstest"
+	"time"
 )
 
 var diffFlag = flag.Bool("diff", false, `
@@ -324,6 +332,1272 @@ func TestTreeFSWithMapFS(t *testing.T) {
 
 3 directories`[1:],
 		},
+		{
+			tcname: "size",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a1.test":   {Data: make([]byte, 12)},
+				"b/b1.test": {Data: make([]byte, 4096)},
+			},
+			opts: []Opt{
+				Size,
+			},
+			expected: `
+.
+├── [         12]  a1.test
+└── [          0]  b
+    └── [       4096]  b1.test
+
+1 directory, 2 files`[1:],
+		},
+		{
+			tcname: "human size",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a1.test": {Data: make([]byte, 12)},
+				"a2.test": {Data: make([]byte, 1536)},
+				"a3.test": {Data: make([]byte, 20<<10)},
+				"a4.test": {Data: make([]byte, 5<<20)},
+			},
+			opts: []Opt{
+				HumanSize,
+			},
+			expected: `
+.
+├── [  12]  a1.test
+├── [1.5K]  a2.test
+├── [ 20K]  a3.test
+└── [5.0M]  a4.test
+
+0 directories, 4 files`[1:],
+		},
+		{
+			tcname: "fixed size",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a1.test": {Data: make([]byte, 12)},
+				"a2.test": {Data: make([]byte, 1536)},
+			},
+			opts: []Opt{
+				FixedSize(KiB, 2),
+			},
+			expected: `
+.
+├── [       0.01 KiB]  a1.test
+└── [       1.50 KiB]  a2.test
+
+0 directories, 2 files`[1:],
+		},
+		{
+			tcname: "atime and ctime",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a1.test": {Sys: stubTimes{
+					atime: time.Date(2020, time.March, 4, 10, 0, 0, 0, time.Local),
+					ctime: time.Date(2019, time.December, 25, 10, 0, 0, 0, time.Local),
+				}},
+				"a2.test": {},
+			},
+			opts: []Opt{
+				ShowATime,
+				ShowCTime,
+				WithFileTimes(stubTimes{}),
+			},
+			expected: `
+.
+├── [Mar  4  2020 Dec 25  2019]  a1.test
+└── [? ?]  a2.test
+
+0 directories, 2 files`[1:],
+		},
+		{
+			tcname: "fold identical",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"locales/de/a.json":   {},
+				"locales/de/b/c.json": {},
+				"locales/en/a.json":   {},
+				"locales/en/b/c.json": {},
+				"locales/fr/a.json":   {},
+				"locales/fr/b/c.json": {},
+				"locales/it/a.json":   {},
+				"locales/x/.keep":     {},
+				"locales/y/.keep":     {},
+			},
+			opts: []Opt{
+				FoldIdentical,
+			},
+			expected: `
+.
+└── locales
+    ├── de
+    │   ├── a.json
+    │   └── b
+    │       └── c.json
+    ├── en [same structure as de]
+    ├── fr [same structure as de]
+    ├── it
+    │   └── a.json
+    ├── x
+    └── y
+
+10 directories, 7 files`[1:],
+		},
+		{
+			tcname: "dir only with trailing file",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.test": {},
+				"b/b1.test": {},
+				"z.test":    {},
+			},
+			opts: []Opt{
+				DirOnly,
+			},
+			expected: `
+.
+├── a
+└── b
+
+2 directories`[1:],
+		},
+		{
+			tcname: "relative mtime",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a1.test": {ModTime: now.Add(-30 * time.Second)},
+				"a2.test": {ModTime: now.Add(-3 * time.Hour)},
+				"a3.test": {ModTime: now.AddDate(0, 0, -3)},
+				"a4.test": {ModTime: now.AddDate(0, -2, -1)},
+				"a5.test": {ModTime: now.AddDate(-2, 0, 0)},
+				"a6.test": {ModTime: now.Add(time.Hour)},
+			},
+			opts: []Opt{
+				ShowMTime,
+				RelativeTime,
+				at(now),
+			},
+			expected: `
+.
+├── [ 30s]  a1.test
+├── [  3h]  a2.test
+├── [  3d]  a3.test
+├── [ 2mo]  a4.test
+├── [  2y]  a5.test
+└── [ -1h]  a6.test
+
+0 directories, 6 files`[1:],
+		},
+		{
+			tcname: "mtime",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a1.test": {ModTime: now.Add(-3 * time.Hour)},
+				"a2.test": {ModTime: now.AddDate(-2, 0, 0)},
+			},
+			opts: []Opt{
+				ShowMTime,
+				at(now),
+			},
+			expected: `
+.
+├── [Jun 15 09:30]  a1.test
+└── [Jun 15  2020]  a2.test
+
+0 directories, 2 files`[1:],
+		},
+		{
+			tcname: "git status",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a1.test":   {},
+				"a2.test":   {},
+				"b/b1.test": {},
+			},
+			opts: []Opt{
+				WithGitStatus(func(p string) string {
+					return map[string]string{"a1.test": " M", "b": "??"}[p]
+				}),
+			},
+			expected: `
+.
+├── [ M]  a1.test
+├── [  ]  a2.test
+└── [??]  b
+    └── [  ]  b1.test
+
+1 directory, 3 files`[1:],
+		},
+		{
+			tcname: "sort by descendants",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a1.test":     {},
+				"b/b1.test":   {},
+				"c/c1.test":   {},
+				"c/d/d1.test": {},
+				"c/d/d2.test": {},
+				"e/e1.test":   {},
+				"e/e2.test":   {},
+				"z.test":      {},
+			},
+			opts: []Opt{
+				SortByDescendants,
+			},
+			expected: `
+.
+├── c
+│   ├── d
+│   │   ├── d1.test
+│   │   └── d2.test
+│   └── c1.test
+├── e
+│   ├── e1.test
+│   └── e2.test
+├── b
+│   └── b1.test
+├── a1.test
+└── z.test
+
+4 directories, 8 files`[1:],
+		},
+		{
+			tcname: "file root",
+			name:   "b/b1.test",
+			mapfs: fstest.MapFS{
+				"a1.test":   {},
+				"b/b1.test": {},
+			},
+			expected: `
+b/b1.test
+
+0 directories, 1 file`[1:],
+		},
+		{
+			tcname: "broken links",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a1.test":   {},
+				"a2.test":   {Data: []byte("a1.test"), Mode: fs.ModeSymlink},
+				"a3.test":   {Data: []byte("missing"), Mode: fs.ModeSymlink},
+				"b/b1.test": {Data: []byte("../a1.test"), Mode: fs.ModeSymlink},
+			},
+			opts: []Opt{
+				BrokenLinks,
+			},
+			expected: `
+.
+├── a1.test
+├── a2.test -> a1.test
+├── a3.test -> missing [broken]
+└── b
+    └── b1.test -> ../a1.test
+
+1 directory, 1 file, 3 symbolic links`[1:],
+		},
+		{
+			tcname: "irregular",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a1.test":   {},
+				"a2.test":   {Mode: fs.ModeIrregular},
+				"b/b1.test": {Mode: fs.ModeIrregular},
+			},
+			expected: `
+.
+├── a1.test
+├── a2.test [irregular]
+└── b
+    └── b1.test [irregular]
+
+1 directory, 1 file, 2 irregular files`[1:],
+		},
+		{
+			tcname: "size range",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a1.test":     {Data: make([]byte, 10)},
+				"a2.test":     {Data: make([]byte, 100)},
+				"b/b1.test":   {Data: make([]byte, 1000)},
+				"c/c1.test":   {Data: make([]byte, 50)},
+				"c/d/d1.test": {Data: make([]byte, 10)},
+				"e/.keep":     {},
+			},
+			opts: []Opt{
+				MinSize(50),
+				MaxSize(500),
+			},
+			expected: `
+.
+├── a2.test
+└── c
+    └── c1.test
+
+1 directory, 2 files`[1:],
+		},
+		{
+			tcname: "mtime range",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a1.test":   {ModTime: now.AddDate(0, 0, -1)},
+				"a2.test":   {ModTime: now.AddDate(0, 0, -10)},
+				"b/b1.test": {ModTime: now.AddDate(0, 0, -30)},
+				"c/c1.test": {ModTime: now.AddDate(0, 0, -5)},
+			},
+			opts: []Opt{
+				NewerThan(now.AddDate(0, 0, -20)),
+				OlderThan(now.AddDate(0, 0, -2)),
+			},
+			expected: `
+.
+├── a2.test
+└── c
+    └── c1.test
+
+1 directory, 2 files`[1:],
+		},
+		{
+			tcname: "depth range",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"README":              {},
+				"pkg/a/a.go":          {},
+				"pkg/a/internal/x.go": {},
+				"pkg/b/b.go":          {},
+				"pkg/doc.go":          {},
+				"cmd/c/main.go":       {},
+			},
+			opts: []Opt{
+				DepthRange(2, 3),
+			},
+			expected: `
+.
+├── cmd/c
+│   └── main.go
+├── pkg/a
+│   ├── a.go
+│   └── internal
+├── pkg/b
+│   └── b.go
+└── pkg/doc.go
+
+4 directories, 4 files`[1:],
+		},
+		{
+			tcname: "elide names",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"short.txt": {},
+				"a_very_long_directory_name/verylongfilename_version.txt": {},
+				"ünïcödé_ñämé_that_is_long.txt":                           {},
+			},
+			opts: []Opt{
+				ElideNames(15),
+				FullPathPrefix,
+			},
+			expected: `
+.
+├── ./a_very_…ry_name
+│   └── ./a_very_…ry_name/verylon…ion.txt
+├── ./short.txt
+└── ./ünïcödé…ong.txt
+
+1 directory, 3 files`[1:],
+		},
+		{
+			tcname: "Fuzzy",
+			mapfs: fstest.MapFS{
+				"src/cmd/main.go":        {},
+				"src/internal/config.go": {},
+				"src/internal/Cache.go":  {},
+				"src/cmd/config":         {Mode: fs.ModeDir},
+				"docs/configuration.md":  {},
+				"docs/index.md":          {},
+			},
+			name: ".",
+			opts: []Opt{Fuzzy("cfg")},
+			expected: `
+.
+├── docs
+│   └── configuration.md
+└── src
+    ├── cmd
+    │   └── config
+    └── internal
+        └── config.go
+
+5 directories, 2 files`[1:],
+		},
+		{
+			// The name of the root is not part of the matched paths.
+			tcname: "Fuzzy named root",
+			mapfs: fstest.MapFS{
+				"src/cmd/main.go":        {},
+				"src/internal/config.go": {},
+				"src/internal/server.go": {},
+			},
+			name: "src",
+			opts: []Opt{Fuzzy("sr")},
+			expected: `
+src
+└── internal
+    └── server.go
+
+1 directory, 1 file`[1:],
+		},
+		{
+			tcname: "junctions",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.test": {},
+				"j":         {Mode: fs.ModeDir | fs.ModeIrregular},
+				"j/j1.test": {},
+			},
+			expected: `
+.
+├── a
+│   └── a1.test
+└── j [junction]
+
+2 directories, 1 file`[1:],
+		},
+		{
+			tcname: "follow junctions",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.test": {},
+				"j":         {Mode: fs.ModeDir | fs.ModeIrregular},
+				"j/j1.test": {},
+			},
+			opts: []Opt{FollowJunctions},
+			expected: `
+.
+├── a
+│   └── a1.test
+└── j [junction]
+    └── j1.test
+
+2 directories, 2 files`[1:],
+		},
+		{
+			tcname: "opaque",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"main.go":                   {},
+				"node_modules/a/index.js":   {},
+				"node_modules/b/index.js":   {},
+				"node_modules/.bin/a":       {},
+				"src/vendor/x/x.go":         {},
+				"src/app.go":                {},
+				"third_party/lib/lib.go":    {},
+				"third_party/lib/README.md": {},
+			},
+			opts: []Opt{Opaque(), Opaque("lib")},
+			expected: `
+.
+├── main.go
+├── node_modules [2 entries]
+├── src
+│   ├── app.go
+│   └── vendor [1 entry]
+└── third_party
+    └── lib [2 entries]
+
+5 directories, 2 files`[1:],
+		},
+		{
+			tcname: "paginate",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.test": {},
+				"a/a2.test": {},
+				"a/a3.test": {},
+				"a/a4.test": {},
+				"b/b1.test": {},
+				"b/b2.test": {},
+				"c.test":    {},
+			},
+			opts: []Opt{Paginate(2)},
+			expected: `
+.
+├── a
+│   ├── a1.test
+│   ├── a2.test
+│   └── … and 2 more
+├── b
+│   ├── b1.test
+│   └── b2.test
+└── … and 1 more
+
+2 directories, 7 files`[1:],
+		},
+		{
+			tcname: "ascii style",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.test": {},
+				"a/a2.test": {},
+				"b.test":    {},
+			},
+			opts: []Opt{WithStyle(ASCIIStyle)},
+			expected: ".\n" +
+				"|-- a\n" +
+				"|   |-- a1.test\n" +
+				"|   `-- a2.test\n" +
+				"`-- b.test\n" +
+				"\n" +
+				"1 directory, 3 files",
+		},
+		{
+			tcname: "partial style",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.test": {},
+				"a/a2.test": {},
+				"b.test":    {},
+			},
+			opts: []Opt{WithStyle(Style{Elbow: "╰──"})},
+			expected: `
+.
+├── a
+│   ├── a1.test
+│   ╰── a2.test
+╰── b.test
+
+1 directory, 3 files`[1:],
+		},
+		{
+			tcname: "classify",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.test": {Mode: 0o644},
+				"fifo":      {Mode: fs.ModeNamedPipe},
+				"link":      {Data: []byte("a"), Mode: fs.ModeSymlink},
+				"run.sh":    {Mode: 0o755},
+				"sock":      {Mode: fs.ModeSocket},
+			},
+			opts: []Opt{Classify},
+			expected: `
+.
+├── a/
+│   └── a1.test
+├── fifo|
+├── link@ -> a
+├── run.sh*
+└── sock=
+
+1 directory, 4 files, 1 symbolic link`[1:],
+		},
+		{
+			tcname: "quote names",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a b/c.test":    {},
+				`say "hi".test`: {},
+			},
+			opts: []Opt{QuoteNames, Classify},
+			expected: `
+.
+├── "a b"/
+│   └── "c.test"
+└── "say \"hi\".test"
+
+1 directory, 2 files`[1:],
+		},
+		{
+			tcname: "disk usage",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a1.test":       {Data: make([]byte, 100)},
+				"b/b1.test":     {Data: make([]byte, 1000)},
+				"b/c/c1.test":   {Data: make([]byte, 24)},
+				"b/c/d/d1.test": {Data: make([]byte, 2000)},
+			},
+			opts: []Opt{DiskUsage},
+			expected: `
+.
+├── [        100]  a1.test
+└── [       3024]  b
+    ├── [       1000]  b1.test
+    └── [       2024]  c
+        ├── [         24]  c1.test
+        └── [       2000]  d
+            └── [       2000]  d1.test
+
+3124 used in 3 directories, 4 files`[1:],
+		},
+		{
+			tcname: "human disk usage",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a1.test":   {Data: make([]byte, 1536)},
+				"b/b1.test": {Data: make([]byte, 3<<10)},
+			},
+			opts: []Opt{HumanSize, DiskUsage},
+			expected: `
+.
+├── [1.5K]  a1.test
+└── [3.0K]  b
+    └── [3.0K]  b1.test
+
+4.5K used in 1 directory, 2 files`[1:],
+		},
+		{
+			tcname: "time format",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a1.test": {ModTime: time.Date(2022, time.June, 1, 9, 5, 0, 0, time.UTC)},
+				"a2.test": {ModTime: time.Date(2019, time.December, 25, 18, 0, 0, 0, time.UTC)},
+			},
+			opts: []Opt{ShowMTime, TimeFormat("2006-01-02 15:04")},
+			expected: `
+.
+├── [2022-06-01 09:05]  a1.test
+└── [2019-12-25 18:00]  a2.test
+
+0 directories, 2 files`[1:],
+		},
+		{
+			tcname: "follow symlinks",
+			name:   "a",
+			mapfs: fstest.MapFS{
+				"a/a1.test": {},
+				"a/b":       {Data: []byte("../b"), Mode: fs.ModeSymlink},
+				"a/c":       {Data: []byte("c1.test"), Mode: fs.ModeSymlink},
+				"a/c1.test": {},
+				"a/self":    {Data: []byte("."), Mode: fs.ModeSymlink},
+				"b/b1.test": {},
+				"b/back":    {Data: []byte("../a"), Mode: fs.ModeSymlink},
+			},
+			opts: []Opt{FollowSymlinks},
+			expected: `
+a
+├── a1.test
+├── b -> ../b
+│   ├── b1.test
+│   └── back -> ../a [recursive, not followed]
+├── c -> c1.test
+├── c1.test
+└── self -> . [recursive, not followed]
+
+3 directories, 3 files, 1 symbolic link`[1:],
+		},
+		{
+			tcname: "sort by size",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a1.test":   {Data: []byte("a")},
+				"a2.test":   {Data: []byte("aaa")},
+				"b/b1.test": {Data: []byte("b")},
+				"b/b2.test": {Data: []byte("bb")},
+				"c.test":    {Data: []byte("cc")},
+			},
+			opts: []Opt{
+				SortBySize,
+			},
+			expected: `
+.
+├── a2.test
+├── c.test
+├── a1.test
+└── b
+    ├── b2.test
+    └── b1.test
+
+1 directory, 5 files`[1:],
+		},
+		{
+			tcname: "version sort",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"file1.test":  {},
+				"file10.test": {},
+				"file2.test":  {},
+				"v1.10/a":     {},
+				"v1.9/a":      {},
+			},
+			opts: []Opt{
+				VersionSort,
+			},
+			expected: `
+.
+├── file1.test
+├── file2.test
+├── file10.test
+├── v1.9
+│   └── a
+└── v1.10
+    └── a
+
+2 directories, 5 files`[1:],
+		},
+		{
+			tcname: "dirs first",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a1.test":     {Data: []byte("a")},
+				"b/b1.test":   {},
+				"c.test":      {Data: []byte("ccc")},
+				"d/d1.test":   {},
+				"d/e/e1.test": {},
+			},
+			opts: []Opt{
+				DirsFirst,
+				SortBySize,
+			},
+			expected: `
+.
+├── b
+│   └── b1.test
+├── d
+│   ├── e
+│   │   └── e1.test
+│   └── d1.test
+├── c.test
+└── a1.test
+
+3 directories, 5 files`[1:],
+		},
+		{
+			tcname: "files first",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.test":   {},
+				"b.test":      {},
+				"c/c1.test":   {},
+				"c/d/d1.test": {},
+				"e.test":      {},
+			},
+			opts: []Opt{
+				DirsFirst,
+				FilesFirst,
+			},
+			expected: `
+.
+├── b.test
+├── e.test
+├── a
+│   └── a1.test
+└── c
+    ├── c1.test
+    └── d
+        └── d1.test
+
+3 directories, 5 files`[1:],
+		},
+		{
+			tcname: "collate",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"apple.test": {},
+				"Zebra.test": {},
+				"Ärger.test": {},
+			},
+			opts: []Opt{
+				// Sorts Ä like A, ignoring case, as a collator would.
+				Collate(func(a, b string) int {
+					fold := strings.NewReplacer("Ä", "a").Replace
+					return strings.Compare(strings.ToLower(fold(a)), strings.ToLower(fold(b)))
+				}),
+			},
+			expected: `
+.
+├── apple.test
+├── Ärger.test
+└── Zebra.test
+
+0 directories, 3 files`[1:],
+		},
+		{
+			tcname: "sort ignore case",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"Makefile":       {},
+				"README.md":      {},
+				"main.go":        {},
+				"readme_test.go": {},
+			},
+			opts: []Opt{
+				SortIgnoreCase,
+			},
+			expected: `
+.
+├── main.go
+├── Makefile
+├── README.md
+└── readme_test.go
+
+0 directories, 4 files`[1:],
+		},
+		{
+			tcname: "match",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.go":   {},
+				"a/a1.test": {},
+				"b/b1.test": {},
+				"c/go.mod":  {},
+				"c/d/d1.go": {},
+				"c/d/d2.md": {},
+				"main.go":   {},
+				"notes.txt": {},
+			},
+			opts: []Opt{
+				Match("*.go|go.mod", "[malformed"),
+			},
+			expected: `
+.
+├── a
+│   └── a1.go
+├── b
+├── c
+│   ├── d
+│   │   └── d1.go
+│   └── go.mod
+└── main.go
+
+4 directories, 4 files`[1:],
+		},
+		{
+			tcname: "match prune",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.go":   {},
+				"a/a1.test": {},
+				"b/b1.test": {},
+				"c/go.mod":  {},
+				"c/d/d1.go": {},
+				"c/d/d2.md": {},
+				"main.go":   {},
+				"notes.txt": {},
+			},
+			opts: []Opt{
+				Match("*.go|go.mod"),
+				Prune,
+			},
+			expected: `
+.
+├── a
+│   └── a1.go
+├── c
+│   ├── d
+│   │   └── d1.go
+│   └── go.mod
+└── main.go
+
+3 directories, 4 files`[1:],
+		},
+		{
+			tcname: "ignore",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.log":             {},
+				"a/a1.test":            {},
+				"main.go":              {},
+				"node_modules/b/b1.js": {},
+				"c/node_modules/c1.js": {},
+				"debug.log":            {},
+			},
+			opts: []Opt{
+				Ignore("node_modules|*.log"),
+			},
+			expected: `
+.
+├── a
+│   └── a1.test
+├── c
+└── main.go
+
+2 directories, 2 files`[1:],
+		},
+		{
+			tcname: "ignore case",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"Build/b1.test": {},
+				"README.MD":     {},
+				"main.go":       {},
+				"notes.md":      {},
+			},
+			opts: []Opt{
+				IgnoreCase,
+				Match("*.md"),
+				Ignore("build"),
+			},
+			expected: `
+.
+├── README.MD
+└── notes.md
+
+0 directories, 2 files`[1:],
+		},
+		{
+			tcname: "match dirs",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.test":      {},
+				"a/a2.go":        {},
+				"docs/b/b1.test": {},
+				"docs/c.txt":     {},
+				"docs/empty":     {Mode: fs.ModeDir},
+				"e/docs/e1.test": {},
+				"main.go":        {},
+			},
+			opts: []Opt{
+				Match("docs|*.go"),
+				MatchDirs,
+			},
+			expected: `
+.
+├── a
+│   └── a2.go
+├── docs
+│   ├── b
+│   │   └── b1.test
+│   ├── c.txt
+│   └── empty
+├── e
+│   └── docs
+│       └── e1.test
+└── main.go
+
+6 directories, 5 files`[1:],
+		},
+		{
+			tcname: "file limit",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.test": {},
+				"a/a2.test": {},
+				"a/a3.test": {},
+				"b/.hidden": {},
+				"b/b1.test": {},
+				"b/b2.test": {},
+				"c.test":    {},
+				"d.test":    {},
+				"e.test":    {},
+			},
+			opts: []Opt{
+				FileLimit(2),
+				MinSize(0),
+			},
+			expected: `
+.
+├── a [3 entries exceeds filelimit]
+├── b
+│   ├── b1.test
+│   └── b2.test
+├── c.test
+├── d.test
+└── e.test
+
+2 directories, 5 files`[1:],
+		},
+		{
+			tcname: "max entries per dir",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.test": {},
+				"a/a2.test": {},
+				"a/a3.test": {},
+				"b.test":    {},
+			},
+			opts: []Opt{MaxEntriesPerDir(1)},
+			expected: `
+.
+├── a
+│   ├── a1.test
+│   └── … 2 more entries
+└── … 1 more entry
+
+1 directory, 4 files`[1:],
+		},
+		{
+			// The last of Paginate and MaxEntriesPerDir applies.
+			tcname: "paginate after max entries per dir",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.test": {},
+				"a/a2.test": {},
+				"a/a3.test": {},
+				"b.test":    {},
+			},
+			opts: []Opt{MaxEntriesPerDir(1), Paginate(1)},
+			expected: `
+.
+├── a
+│   ├── a1.test
+│   └── … and 2 more
+└── … and 1 more
+
+1 directory, 4 files`[1:],
+		},
+		{
+			tcname: "min level",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a.test":                     {},
+				"services/api/handlers/u.go": {},
+				"services/api/main.go":       {},
+				"services/web/pages/i.go":    {},
+			},
+			opts: []Opt{
+				Level(3),
+				MinLevel(3),
+			},
+			expected: `
+.
+├── services/api/handlers
+├── services/api/main.go
+└── services/web/pages
+
+2 directories, 1 file`[1:],
+		},
+		{
+			tcname: "extensions",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.go":      {},
+				"a/a1_test.go": {},
+				"b/b1.md":      {},
+				"go.mod":       {},
+				"go.sum":       {},
+				"main.go":      {},
+			},
+			opts: []Opt{
+				Extensions(".go", "mod", ""),
+				ExcludeExtensions("_test.go", ".mod"),
+			},
+			expected: `
+.
+├── a
+│   ├── a1.go
+│   └── a1_test.go
+└── main.go
+
+1 directory, 3 files`[1:],
+		},
+		{
+			tcname: "filter",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.test": {},
+				"a/a2.skip": {},
+				"b/b1.test": {},
+				"c.test":    {},
+				"d.skip":    {},
+			},
+			opts: []Opt{
+				Filter(func(p string, d fs.DirEntry) bool {
+					return p != "b" && !strings.HasSuffix(p, ".skip")
+				}),
+			},
+			expected: `
+.
+├── a
+│   └── a1.test
+└── c.test
+
+1 directory, 2 files`[1:],
+		},
+		{
+			tcname: "collapse",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"main.go":                     {},
+				"node_modules/a/index.js":     {},
+				"node_modules/a/package.json": {},
+				"node_modules/b/index.js":     {},
+				"web/node_modules/c/index.js": {},
+			},
+			opts: []Opt{
+				Collapse("node_modules"),
+			},
+			expected: `
+.
+├── main.go
+├── node_modules [5 entries]
+└── web
+    └── node_modules [2 entries]
+
+6 directories, 5 files`[1:],
+		},
+		{
+			tcname: "dedupe identical",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"locales/de/a.json": {},
+				"locales/en/a.json": {},
+				"locales/fr/a.json": {},
+				"locales/it/b.json": {},
+				"locales/pt/b.json": {},
+				"locales/README.md": {},
+			},
+			opts: []Opt{
+				DedupeIdentical,
+			},
+			expected: `
+.
+└── locales
+    ├── README.md
+    ├── de
+    │   └── a.json
+    ├── … 2 identical siblings
+    ├── it
+    │   └── b.json
+    └── … 1 identical sibling
+
+6 directories, 6 files`[1:],
+		},
+		{
+			tcname: "no report",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.test": {},
+				"b.test":    {},
+			},
+			opts: []Opt{
+				NoReport,
+			},
+			expected: `
+.
+├── a
+│   └── a1.test
+└── b.test`[1:],
+		},
+		{
+			tcname: "meta formatter",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.test": {},
+				"b.test":    {},
+			},
+			opts: []Opt{
+				MetaFormatter(func(ndirs, nfiles int) string {
+					return fmt.Sprintf("%d Ordner, %d Dateien", ndirs, nfiles)
+				}),
+			},
+			expected: `
+.
+├── a
+│   └── a1.test
+└── b.test
+
+1 Ordner, 2 Dateien`[1:],
+		},
+		{
+			tcname: "count all",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.test":     {},
+				"a/b/b1.test":   {},
+				"a/b/c/c1.test": {},
+				"d.test":        {},
+			},
+			opts: []Opt{
+				Level(2),
+				CountAll,
+			},
+			expected: `
+.
+├── a
+│   ├── a1.test
+│   └── b
+└── d.test
+
+3 directories, 4 files`[1:],
+		},
+		{
+			tcname: "count visible only",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				".hidden":     {},
+				"a/a1.test":   {},
+				"a/a2.test":   {},
+				"a/a3.test":   {},
+				"b/b1.test":   {},
+				"b/c/c1.test": {},
+			},
+			opts: []Opt{
+				CountVisibleOnly,
+				Level(2),
+				CountAll,
+				Paginate(2),
+			},
+			expected: `
+.
+├── a
+│   ├── a1.test
+│   ├── a2.test
+│   └── … and 1 more
+└── b
+    ├── b1.test
+    └── c
+
+3 directories, 3 files`[1:],
+		},
+		{
+			tcname: "dir counts",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.test":     {},
+				"a/b/b1.test":   {},
+				"a/b/b2.test":   {},
+				"a/c/d/d1.test": {},
+				"e.test":        {},
+				"vendor/x.go":   {},
+			},
+			opts: []Opt{
+				DirCounts,
+				Opaque("vendor"),
+			},
+			expected: `
+.
+├── a (4 files, 3 dirs)
+│   ├── a1.test
+│   ├── b (2 files, 0 dirs)
+│   │   ├── b1.test
+│   │   └── b2.test
+│   └── c (1 file, 1 dir)
+│       └── d (1 file, 0 dirs)
+│           └── d1.test
+├── e.test
+└── vendor [1 entry]
+
+5 directories, 5 files`[1:],
+		},
+		{
+			tcname: "total size",
+			name:   ".",
+			mapfs: fstest.MapFS{
+				"a/a1.bin": {Data: make([]byte, 1500)},
+				"b.bin":    {Data: make([]byte, 2000)},
+			},
+			opts: []Opt{
+				TotalSize,
+			},
+			expected: `
+.
+├── a
+│   └── a1.bin
+└── b.bin
+
+1 directory, 2 files, 3,500 bytes (3.4K)`[1:],
+		},
+		{
+			tcname: "total size of file",
+			name:   "b.bin",
+			mapfs: fstest.MapFS{
+				"b.bin": {Data: make([]byte, 1)},
+			},
+			opts: []Opt{
+				TotalSize,
+			},
+			expected: `
+b.bin
+
+0 directories, 1 file, 1 byte (1)`[1:],
+		},
 	}
 
 	for _, tc := range tests {
@@ -339,6 +1613,98 @@ func TestTreeFSWithMapFS(t *testing.T) {
 	}
 }
 
+// The fixed time that tests measure relative times from.
+var now = time.Date(2022, time.June, 15, 12, 30, 0, 0, time.Local)
+
+// Measure relative times from tm rather than the current time.
+func at(tm time.Time) Opt {
+	return func(t *TreeFS) {
+		t.now = func() time.Time { return tm }
+	}
+}
+
+// stubTimes is used both as the Sys() of a fstest.MapFile and as the
+// FileTimes extracting the times it holds.
+type stubTimes struct {
+	atime, ctime time.Time
+}
+
+func (stubTimes) ATime(fi fs.FileInfo) (time.Time, bool) {
+	st, ok := fi.Sys().(stubTimes)
+	return st.atime, ok
+}
+
+func (stubTimes) CTime(fi fs.FileInfo) (time.Time, bool) {
+	st, ok := fi.Sys().(stubTimes)
+	return st.ctime, ok
+}
+
+// errFS wraps an fstest.MapFS, failing ReadDir for the directories in dirs
+// and Info for the entries in infos.
+type errFS struct {
+	fstest.MapFS
+	dirs  map[string]bool
+	infos map[string]bool
+}
+
+var errUnreadable = errors.New("unreadable")
+
+func (e errFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if e.dirs[name] {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errUnreadable}
+	}
+	entries, err := e.MapFS.ReadDir(name)
+	for i, entry := range entries {
+		if e.infos[path.Join(name, entry.Name())] {
+			entries[i] = errEntry{entry}
+		}
+	}
+	return entries, err
+}
+
+type errEntry struct {
+	fs.DirEntry
+}
+
+func (errEntry) Info() (fs.FileInfo, error) {
+	return nil, errUnreadable
+}
+
+func TestStrict(t *testing.T) {
+	fsys := errFS{
+		MapFS: fstest.MapFS{
+			"a1.test":     {},
+			"b/b1.test":   {},
+			"c/c1.test":   {},
+			"c/d/d1.test": {},
+		},
+		dirs:  map[string]bool{"b": true, "c/d": true},
+		infos: map[string]bool{"c/c1.test": true},
+	}
+
+	if _, err := New(fsys, "."); !errors.Is(err, errUnreadable) {
+		t.Fatalf("expected first ReadDir error without Strict, got %v", err)
+	}
+
+	_, err := New(fsys, ".", Strict)
+	var strictErr *StrictError
+	if !errors.As(err, &strictErr) {
+		t.Fatalf("expected *StrictError, got %v", err)
+	}
+	if !errors.Is(err, errUnreadable) {
+		t.Fatalf("expected StrictError to wrap %v", errUnreadable)
+	}
+
+	expected := []string{"b", "c/c1.test", "c/d"}
+	if got := strictErr.Paths(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected paths %v, got %v", expected, got)
+	}
+
+	if _, err := New(fstest.MapFS{"a1.test": {}}, ".", Strict); err != nil {
+		t.Fatalf("expected no error for readable fs, got %v", err)
+	}
+}
+
 func compare(t *testing.T, got, expected string) {
 	if strings.Compare(got, expected) != 0 {
 		dif := ""
@@ -384,3 +1750,460 @@ func diff(t *testing.T, a, b string) string {
 
 	return outb.String()
 }
+
+func TestRootErrors(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a1.test": {},
+		"fifo":    {Mode: fs.ModeNamedPipe},
+	}
+
+	tests := []struct {
+		name     string
+		expected error
+		is       error
+	}{
+		{name: "/a1.test", expected: ErrInvalidName, is: fs.ErrInvalid},
+		{name: "a/", expected: ErrInvalidName, is: fs.ErrInvalid},
+		{name: "missing", expected: ErrNotExist, is: fs.ErrNotExist},
+		{name: "fifo", expected: ErrNotDir},
+	}
+
+	for _, tc := range tests {
+		_, err := New(mapfs, tc.name)
+		var pathErr *fs.PathError
+		if !errors.As(err, &pathErr) || pathErr.Path != tc.name {
+			t.Fatalf("%s: expected *fs.PathError, got %v", tc.name, err)
+		}
+		if !errors.Is(err, tc.expected) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.expected, err)
+		}
+		if tc.is != nil && !errors.Is(err, tc.is) {
+			t.Fatalf("%s: expected error to also be %v", tc.name, tc.is)
+		}
+	}
+}
+
+// countFS wraps an fstest.MapFS, counting the calls to the Info method of
+// its entries.
+type countFS struct {
+	fstest.MapFS
+	infos *int
+}
+
+func (c countFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := c.MapFS.ReadDir(name)
+	for i, entry := range entries {
+		entries[i] = countEntry{entry, c.infos}
+	}
+	return entries, err
+}
+
+type countEntry struct {
+	fs.DirEntry
+	infos *int
+}
+
+func (c countEntry) Info() (fs.FileInfo, error) {
+	*c.infos++
+	return c.DirEntry.Info()
+}
+
+func TestInfoCached(t *testing.T) {
+	var infos int
+	fsys := countFS{
+		MapFS: fstest.MapFS{
+			"a1.test":   {},
+			"b/b1.test": {},
+		},
+		infos: &infos,
+	}
+
+	if _, err := New(fsys, ".", Strict, Size, ShowMTime, ShowATime, ShowCTime); err != nil {
+		t.Fatal(err)
+	}
+	if infos != 3 {
+		t.Fatalf("expected Info to be called once per entry (3), got %d", infos)
+	}
+}
+
+func TestAccessors(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a1.test":   {},
+		"a2.test":   {},
+		"b/b1.test": {},
+		"b/c/.keep": {},
+	}
+
+	tfs, err := New(mapfs, ".", Hidden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tfs.Len() != 7 || tfs.NumEntries() != 6 {
+		t.Fatalf("expected 7 lines and 6 entries, got %d and %d", tfs.Len(), tfs.NumEntries())
+	}
+	if tfs.Depth() != 3 {
+		t.Fatalf("expected depth 3, got %d", tfs.Depth())
+	}
+
+	multi, err := NewMulti(Arg{Fsys: mapfs, Name: "."}, Arg{Fsys: mapfs, Name: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if multi.Len() != 9 || multi.NumEntries() != 7 {
+		t.Fatalf("expected 9 lines and 7 entries, got %d and %d", multi.Len(), multi.NumEntries())
+	}
+}
+
+func TestNewMultiPartial(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.test": {},
+		"b/b1.test": {},
+	}
+
+	tfs, errs, err := NewMultiPartial(
+		Arg{Fsys: mapfs, Name: "a"},
+		Arg{Fsys: mapfs, Name: "missing"},
+		Arg{Fsys: mapfs, Name: "/b"},
+		Arg{Fsys: mapfs, Name: "b"},
+	)
+	if !errors.Is(err, ErrNotExist) || !errors.Is(err, ErrInvalidName) {
+		t.Fatalf("expected ErrNotExist and ErrInvalidName, got %v", err)
+	}
+	if len(errs) != 4 || errs[0] != nil || errs[1] == nil || errs[2] == nil || errs[3] != nil {
+		t.Fatalf("expected errors for the second and third Args, got %v", errs)
+	}
+
+	expected := `a
+└── a1.test
+b
+└── b1.test
+
+0 directories, 2 files`
+	compare(t, tfs.String(), expected)
+
+	if _, errs, err := NewMultiPartial(Arg{Fsys: mapfs, Name: "a"}); err != nil || len(errs) != 1 || errs[0] != nil {
+		t.Fatalf("expected no errors, got %v", err)
+	}
+}
+
+func TestNewMultiContext(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.test":   {},
+		"a/b/b1.test": {},
+		"c/c1.test":   {},
+	}
+	args := []Arg{
+		{Fsys: mapfs, Name: "a"},
+		{Fsys: mapfs, Name: "c", Opts: []Opt{FullPathPrefix}},
+		{Fsys: mapfs, Name: "."},
+	}
+
+	expected, err := NewMulti(args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := NewMultiContext(context.Background(), args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, got.String(), expected.String())
+
+	_, err = NewMultiContext(context.Background(), append(args, Arg{Fsys: mapfs, Name: "missing"})...)
+	if !errors.Is(err, ErrNotExist) {
+		t.Fatalf("expected ErrNotExist, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = NewMultiContext(ctx, args...); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestNewMultiContextSharedOpts(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.test": {Data: []byte("a1\n")},
+		"b/b1.test": {Data: []byte("b1\n")},
+		"c/c1.test": {Data: []byte("c1\n")},
+	}
+	// The Opts normalizing their arguments are applied concurrently.
+	opts := []Opt{Preview(0), FixedSize(KiB, -1), ContentMatch(regexp.MustCompile("1"), 0)}
+	var args []Arg
+	for _, name := range []string{"a", "b", "c", "a", "b", "c"} {
+		args = append(args, Arg{Fsys: mapfs, Name: name, Opts: opts})
+	}
+
+	got, err := NewMultiContext(context.Background(), args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := NewMulti(args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, got.String(), expected.String())
+}
+
+func TestThousands(t *testing.T) {
+	for n, expected := range map[int]string{
+		0:        "0",
+		999:      "999",
+		3482:     "3,482",
+		1000000:  "1,000,000",
+		-1234567: "-1,234,567",
+	} {
+		if got := thousands(n); got != expected {
+			t.Fatalf("expected %q for %d, got %q", expected, n, got)
+		}
+	}
+}
+
+func TestSymlinkTargets(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.test": {},
+		"a/link":    {Data: []byte("a1.test"), Mode: fs.ModeSymlink},
+	}
+
+	tfs, err := New(mapfs, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, tfs.String(), `
+a
+├── a1.test
+└── link -> a1.test
+
+0 directories, 1 file, 1 symbolic link`[1:])
+
+	// Without fs.ReadLinkFS, the target is unknown.
+	tfs, err = New(struct{ fs.FS }{mapfs}, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare(t, tfs.Graph(), `
+a
+├── a1.test
+└── link`[1:])
+}
+
+func TestNScanned(t *testing.T) {
+	mapfs := fstest.MapFS{
+		".hidden":   {},
+		"a/a1.test": {},
+		"a/a2.log":  {},
+		"b.test":    {},
+	}
+
+	tfs, err := New(mapfs, ".", Ignore("*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tfs.NScanned != 5 {
+		t.Errorf("expected 5 entries scanned, got %d", tfs.NScanned)
+	}
+	if n := tfs.NDirs + tfs.NFiles; n != 3 {
+		t.Errorf("expected 3 entries counted, got %d", n)
+	}
+}
+
+func TestRoot(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.test":   {Data: []byte("hello")},
+		"a/b/b1.test": {},
+	}
+
+	var empty TreeFS
+	if root := empty.Root(); root != nil {
+		t.Fatalf("expected no root, got %v", root)
+	}
+
+	tfs, err := New(mapfs, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := tfs.Root()
+	if root.Name != "a" || root.Path != "a" || !root.IsDir || len(root.Children) != 2 {
+		t.Fatalf("unexpected root %+v", root)
+	}
+
+	a1, b := root.Children[0], root.Children[1]
+	if a1.Path != "a/a1.test" || a1.IsDir || a1.Info == nil || a1.Info.Size() != 5 {
+		t.Errorf("unexpected entry %+v", a1)
+	}
+	if b.Path != "a/b" || !b.IsDir || len(b.Children) != 1 || b.Children[0].Name != "b1.test" {
+		t.Errorf("unexpected entry %+v", b)
+	}
+
+	tfs, err = NewMulti(Arg{Fsys: mapfs, Name: "a"}, Arg{Fsys: mapfs, Name: "a/b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if roots := tfs.Roots(); len(roots) != 2 || roots[1].Path != "a/b" {
+		t.Errorf("unexpected roots %v", roots)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.test":   {},
+		"a/b/b1.test": {},
+		"a/c/c1.test": {},
+		"a/c/c2.test": {},
+		"a/d/d1.test": {},
+		"a/e.test":    {},
+	}
+
+	tfs, err := New(mapfs, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var visited []string
+	err = tfs.Walk(func(n *Node, depth int) error {
+		visited = append(visited, fmt.Sprintf("%d:%s", depth, n.Path))
+		switch n.Path {
+		case "a/b":
+			return fs.SkipDir
+		case "a/c/c1.test":
+			return fs.SkipDir
+		case "a/d/d1.test":
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"0:a", "1:a/a1.test", "1:a/b", "1:a/c", "2:a/c/c1.test", "1:a/d", "2:a/d/d1.test"}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("expected %v, got %v", expected, visited)
+	}
+
+	errStop := errors.New("stop")
+	if err := tfs.Walk(func(*Node, int) error { return errStop }); err != errStop {
+		t.Errorf("expected %v, got %v", errStop, err)
+	}
+
+	// The Info of the Nodes is only loaded by Stat.
+	err = tfs.Walk(func(n *Node, depth int) error {
+		if n.Info != nil {
+			t.Errorf("%s: expected the Info not to be loaded", n.Path)
+		}
+		if depth == 0 {
+			return nil
+		}
+		if fi, err := n.Stat(); err != nil || fi.Name() != n.Name {
+			t.Errorf("%s: expected its Info, got %v, %v", n.Path, fi, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAll(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.test":   {},
+		"a/b/b1.test": {},
+		"a/c.test":    {},
+	}
+
+	tfs, err := New(mapfs, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for p, n := range tfs.All() {
+		if p != n.Path {
+			t.Errorf("expected path %q, got %q", n.Path, p)
+		}
+		paths = append(paths, p)
+		if p == "a/b/b1.test" {
+			break
+		}
+	}
+	expected := []string{"a", "a/a1.test", "a/b", "a/b/b1.test"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %v, got %v", expected, paths)
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.test":   {},
+		"a/b/b1.test": {},
+	}
+
+	for _, opts := range [][]Opt{nil, {DeepestReport(1)}, {NoReport}, {OnLine(func(string) {})}} {
+		tfs, err := New(mapfs, "a", opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var b strings.Builder
+		n, err := tfs.WriteTo(&b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		compare(t, b.String(), tfs.String()+"\n")
+		if n != int64(b.Len()) {
+			t.Errorf("expected %d bytes written, got %d", b.Len(), n)
+		}
+	}
+
+	tfs, err := New(mapfs, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tfs.WriteTo(failWriter{}); !errors.Is(err, errWrite) {
+		t.Errorf("expected %v, got %v", errWrite, err)
+	}
+}
+
+func TestFprint(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.test":   {},
+		"a/b/b1.test": {},
+	}
+
+	tests := []struct {
+		name   string
+		fprint func(io.Writer, fs.FS, string, ...Opt) error
+		str    func(fs.FS, string, ...Opt) (string, error)
+	}{
+		{"Fprint", Fprint, Tree},
+		{"Fgraph", Fgraph, Graph},
+		{"Fmeta", Fmeta, Meta},
+	}
+	for _, tc := range tests {
+		for _, opts := range [][]Opt{nil, {DeepestReport(1)}} {
+			var b strings.Builder
+			if err := tc.fprint(&b, mapfs, "a", opts...); err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			expected, err := tc.str(mapfs, "a", opts...)
+			if err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			compare(t, b.String(), expected+"\n")
+		}
+	}
+
+	if err := Fprint(failWriter{}, mapfs, "a"); !errors.Is(err, errWrite) {
+		t.Errorf("expected %v, got %v", errWrite, err)
+	}
+	// Nothing is written for a root that can't be graphed.
+	for _, tc := range tests {
+		for name, expected := range map[string]error{"missing": ErrNotExist, "/a": ErrInvalidName} {
+			var b strings.Builder
+			if err := tc.fprint(&b, mapfs, name); !errors.Is(err, expected) {
+				t.Errorf("%s %q: expected %v, got %v", tc.name, name, expected, err)
+			}
+			if b.Len() > 0 {
+				t.Errorf("%s %q: expected nothing to be written, got %q", tc.name, name, b.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package treefs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Builder builds a TreeFS from the entries visited by fs.WalkDir or
+// filepath.WalkDir, so that code already structured around a walk can graph
+// it without walking the filesystem a second time:
+//
+//	b := NewBuilder(Hidden)
+//	if err := fs.WalkDir(fsys, ".", b.WalkDirFunc); err != nil {
+//		log.Fatal(err)
+//	}
+//	tfs, err := b.TreeFS()
+//
+// Errors passed to WalkDirFunc are reported like those of New, e.g. with the
+// Strict Opt. Since the contents of files are not recorded, the Opts reading
+// them, i.e. Preview, Manifest, ContentMatch, MIME and IgnoreFile, are not
+// supported, and TreeFS fails if any of them was applied.
+type Builder struct {
+	tmpl TreeFS   // TreeFS with the Opts applied
+	rec  recordFS // the entries visited so far
+	root string   // the slash-separated path of the root of the walk
+}
+
+// NewBuilder returns a Builder that builds a TreeFS with the Opts opts.
+func NewBuilder(opts ...Opt) *Builder {
+	b := &Builder{tmpl: defaults()}
+	for _, opt := range opts {
+		opt(&b.tmpl)
+	}
+	return b
+}
+
+// WalkDirFunc records the entry d with path p, or the error err, in b. It
+// is an fs.WalkDirFunc, and never stops the walk.
+//
+// The first path it is called with is the root of the graph.
+func (b *Builder) WalkDirFunc(p string, d fs.DirEntry, err error) error {
+	p = filepath.ToSlash(p)
+	if b.rec.entries == nil {
+		b.root = p
+		b.rec = recordFS{
+			entries:  map[string]fs.DirEntry{},
+			children: map[string][]fs.DirEntry{},
+			errs:     map[string]error{},
+		}
+	}
+
+	rel := b.rel(p)
+	if err != nil {
+		// Either d is the directory that failed to be read or, if d is
+		// nil, p itself couldn't be stat'ed.
+		b.rec.errs[rel] = err
+		if d == nil {
+			return nil
+		}
+	}
+	if _, ok := b.rec.entries[rel]; ok {
+		return nil
+	}
+
+	b.rec.entries[rel] = d
+	if rel != "." {
+		dir := path.Dir(rel)
+		b.rec.children[dir] = append(b.rec.children[dir], d)
+	}
+	return nil
+}
+
+// TreeFS returns the TreeFS of the entries recorded by WalkDirFunc, with the
+// same errors as New.
+func (b *Builder) TreeFS() (tfs TreeFS, err error) {
+	if opt := b.tmpl.contentOpt(); opt != "" {
+		return tfs, fmt.Errorf("treefs: %s reads the contents of files, which a Builder doesn't record: %w", opt, errors.ErrUnsupported)
+	}
+
+	tfs = b.tmpl
+	if fs.ValidPath(b.root) {
+		b.rec.root = b.root
+		err = build(&tfs, b.rec, b.root)
+		return
+	}
+
+	// Roots that aren't valid in an fs.FS, e.g. absolute ones, are graphed
+	// as is, but read from ".".
+	tfs.pathPrefix = strings.TrimSuffix(b.root, "/")
+	err = buildAt(&tfs, b.rec, b.root, ".")
+	return
+}
+
+// Return the name of the first Opt applied to t that reads the contents of
+// files, or "" if none was.
+func (t *TreeFS) contentOpt() string {
+	switch {
+	case t.previewBytes > 0:
+		return "Preview"
+	case t.manifest != nil:
+		return "Manifest"
+	case t.contentRe != nil:
+		return "ContentMatch"
+	case t.mimeTypes:
+		return "MIME"
+	case t.ignoreFile != "":
+		return "IgnoreFile"
+	}
+	return ""
+}
+
+// Return the path p relative to the root of the walk.
+func (b *Builder) rel(p string) string {
+	if p == b.root {
+		return "."
+	}
+	if b.root == "." {
+		return p
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(p, b.root), "/")
+}
+
+// recordFS is the fs.FS of the entries recorded by a Builder, keyed by their
+// path relative to the root of the walk.
+type recordFS struct {
+	root     string                   // the path of the root, if valid
+	entries  map[string]fs.DirEntry   // the entries by path
+	children map[string][]fs.DirEntry // the entries of each directory
+	errs     map[string]error         // the errors of the walk by path
+}
+
+// Return the key of the entry with the name name of the fs.FS r.
+func (r recordFS) key(name string) string {
+	switch {
+	case r.root == "" || r.root == ".":
+		return name
+	case name == r.root:
+		return "."
+	}
+	return strings.TrimPrefix(name, r.root+"/")
+}
+
+func (r recordFS) Open(name string) (fs.File, error) {
+	key := r.key(name)
+	d, ok := r.entries[key]
+	if !ok {
+		if err := r.errs[key]; err != nil {
+			return nil, err
+		}
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &recordFile{r: r, key: key, entry: d}, nil
+}
+
+func (r recordFS) Stat(name string) (fs.FileInfo, error) {
+	f, err := r.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return f.Stat()
+}
+
+func (r recordFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	key := r.key(name)
+	if _, ok := r.entries[key]; !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	if err := r.errs[key]; err != nil {
+		return r.children[key], err
+	}
+	return r.children[key], nil
+}
+
+// recordFile is a file of a recordFS. Only its metadata, and the entries of
+// directories, can be read.
+type recordFile struct {
+	r      recordFS
+	key    string
+	entry  fs.DirEntry
+	offset int // the number of entries returned by ReadDir so far
+}
+
+func (f *recordFile) Stat() (fs.FileInfo, error) {
+	return f.entry.Info()
+}
+
+func (f *recordFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: f.key, Err: fs.ErrInvalid}
+}
+
+func (f *recordFile) Close() error { return nil }
+
+func (f *recordFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	entries := f.r.children[f.key][f.offset:]
+	if n > 0 && len(entries) > n {
+		entries = entries[:n]
+	}
+	f.offset += len(entries)
+	if n > 0 && len(entries) == 0 {
+		return nil, io.EOF
+	}
+	return entries, nil
+}
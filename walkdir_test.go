@@ -0,0 +1,139 @@
+package treefs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestBuilder(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.test":       {},
+		"a/.a2.test":      {},
+		"a/b/b1.test":     {},
+		"a/b/c/c1.test":   {},
+		"a/d/.d1.test":    {},
+		"a/e/e1.test":     {},
+		"a/e/f/f1.test":   {},
+		"a/e/f/g/g1.test": {},
+	}
+
+	for _, tc := range []struct {
+		tcname string
+		name   string
+		opts   []Opt
+	}{
+		{tcname: "root", name: "."},
+		{tcname: "subdirectory", name: "a/e"},
+		{tcname: "options", name: "a", opts: []Opt{Hidden, Level(2), FullPathPrefix}},
+		{tcname: "file", name: "a/a1.test"},
+	} {
+		t.Run(tc.tcname, func(t *testing.T) {
+			expected, err := New(mapfs, tc.name, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b := NewBuilder(tc.opts...)
+			if err := fs.WalkDir(mapfs, tc.name, b.WalkDirFunc); err != nil {
+				t.Fatal(err)
+			}
+			got, err := b.TreeFS()
+			if err != nil {
+				t.Fatal(err)
+			}
+			compare(t, got.String(), expected.String())
+		})
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	fsys := errFS{
+		MapFS: fstest.MapFS{
+			"a/a1.test":   {},
+			"a/b/b1.test": {},
+		},
+		dirs: map[string]bool{"a/b": true},
+	}
+
+	b := NewBuilder(Strict)
+	if err := fs.WalkDir(fsys, "a", b.WalkDirFunc); err != nil {
+		t.Fatal(err)
+	}
+	_, err := b.TreeFS()
+	var serr *StrictError
+	if !errors.As(err, &serr) || !errors.Is(err, errUnreadable) {
+		t.Fatalf("expected StrictError wrapping %v, got %v", errUnreadable, err)
+	}
+	if paths := serr.Paths(); len(paths) != 1 || paths[0] != "a/b" {
+		t.Fatalf("expected failing path a/b, got %v", paths)
+	}
+
+	b = NewBuilder()
+	if err := fs.WalkDir(fsys, "missing", b.WalkDirFunc); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.TreeFS(); !errors.Is(err, ErrNotExist) {
+		t.Fatalf("expected ErrNotExist, got %v", err)
+	}
+}
+
+func TestBuilderContents(t *testing.T) {
+	mapfs := fstest.MapFS{
+		"a/a1.test": {Data: []byte("a1")},
+	}
+
+	for name, opt := range map[string]Opt{
+		"Preview":      Preview(10),
+		"Manifest":     Manifest(io.Discard),
+		"ContentMatch": ContentMatch(regexp.MustCompile("a"), 0),
+		"MIME":         MIME,
+		"IgnoreFile":   IgnoreFile(".gitignore"),
+	} {
+		b := NewBuilder(opt)
+		if err := fs.WalkDir(mapfs, "a", b.WalkDirFunc); err != nil {
+			t.Fatal(err)
+		}
+		_, err := b.TreeFS()
+		if !errors.Is(err, errors.ErrUnsupported) || !strings.Contains(err.Error(), name) {
+			t.Errorf("%s: expected an unsupported error naming it, got %v", name, err)
+		}
+	}
+}
+
+func TestBuilderAbsolute(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a/a1.test", "b1.test"} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b := NewBuilder(FullPathPrefix)
+	if err := filepath.WalkDir(dir, b.WalkDirFunc); err != nil {
+		t.Fatal(err)
+	}
+	got, err := b.TreeFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := filepath.ToSlash(dir)
+	expected := root + "\n" +
+		"├── " + root + "/a\n" +
+		"│   └── " + root + "/a/a1.test\n" +
+		"└── " + root + "/b1.test\n" +
+		"\n" +
+		"1 directory, 2 files"
+	compare(t, got.String(), expected)
+}
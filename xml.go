@@ -0,0 +1,71 @@
+package treefs
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// XML returns the XML document of the TreeFS t, as produced by `tree -X`: a
+// <tree> element with the nested entries of each root, followed by a
+// <report> with the number of directories and files.
+//
+// Entries include their size and modification time if Size, HumanSize or
+// FixedSize, and ShowMTime, were applied.
+func (t TreeFS) XML() string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString("<tree>\n")
+	for _, root := range t.roots {
+		t.writeXML(&b, root, 1)
+	}
+
+	b.WriteString("  <report>\n")
+	fmt.Fprintf(&b, "    <directories>%d</directories>\n", t.NDirs)
+	if !t.dirOnly {
+		fmt.Fprintf(&b, "    <files>%d</files>\n", t.NFiles+t.NIrregular+t.NSymlinks)
+	}
+	b.WriteString("  </report>\n</tree>")
+	return b.String()
+}
+
+// Write the XML element of the Node n, and its children, to b, indented as
+// at depth depth.
+func (t *TreeFS) writeXML(b *strings.Builder, n *Node, depth int) {
+	indent := strings.Repeat("  ", depth)
+	typ := entryType(n.mode())
+
+	b.WriteString(indent + "<" + typ)
+	writeXMLAttr(b, "name", n.Name)
+	if n.Target != "" {
+		writeXMLAttr(b, "target", n.Target)
+	}
+	if fi, _ := n.info(); fi != nil {
+		if t.sizeFmt != nil {
+			writeXMLAttr(b, "size", fmt.Sprint(fi.Size()))
+		}
+		if t.mtime {
+			writeXMLAttr(b, "time", t.formatTime(fi, modTime))
+		}
+	}
+	b.WriteString(">")
+
+	if !n.IsDir {
+		b.WriteString("</" + typ + ">\n")
+		return
+	}
+
+	b.WriteString("\n")
+	for _, child := range n.Children {
+		t.writeXML(b, child, depth+1)
+	}
+	b.WriteString(indent + "</" + typ + ">\n")
+}
+
+// Write the attribute key, with the escaped value value, to b.
+func writeXMLAttr(b *strings.Builder, key, value string) {
+	b.WriteString(" " + key + `="`)
+	// Writing to a strings.Builder can't fail.
+	_ = xml.EscapeText(b, []byte(value))
+	b.WriteString(`"`)
+}
@@ -0,0 +1,58 @@
+package treefs
+
+import (
+	"encoding/xml"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestXML(t *testing.T) {
+	tfs, err := New(testFS, "testdata/a/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Output of `tree -X testdata/a/b` from directory containing testdata/.
+	expected := `
+<?xml version="1.0" encoding="UTF-8"?>
+<tree>
+  <directory name="testdata/a/b">
+    <file name="b1.test"></file>
+    <file name="b2.test"></file>
+    <file name="b3.test"></file>
+    <directory name="d">
+      <file name="d1.test"></file>
+    </directory>
+  </directory>
+  <report>
+    <directories>1</directories>
+    <files>4</files>
+  </report>
+</tree>`[1:]
+	compare(t, tfs.XML(), expected)
+
+	mapfs := fstest.MapFS{
+		`a<"&">.test`: {Data: make([]byte, 12)},
+		"e":           {Mode: fs.ModeDir | 0o755},
+	}
+	tfs, err = New(mapfs, ".", Size, DirOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := xml.Unmarshal([]byte(tfs.XML()), new(struct{})); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, tfs.XML())
+	}
+	if !strings.Contains(tfs.XML(), `<directory name="e" size="0">`) || strings.Contains(tfs.XML(), "<files>") {
+		t.Fatalf("unexpected XML:\n%s", tfs.XML())
+	}
+
+	tfs, err = New(mapfs, ".", Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(tfs.XML(), `<file name="a&lt;&#34;&amp;&#34;&gt;.test" size="12"></file>`) {
+		t.Fatalf("expected escaped name, got:\n%s", tfs.XML())
+	}
+}
@@ -0,0 +1,99 @@
+package treefs
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// YAML returns a YAML document of the TreeFS t mapping each root, and each
+// directory below it, to a mapping of its entries.
+//
+// Files are mapped to null, or, if any keys are given, to a mapping of the
+// metadata of the file with those keys. The supported keys are "type",
+// "size", "mode" and "mtime"; others are ignored:
+//
+//	testdata/a/b:
+//	  b1.test: {size: 12, mtime: "2022-06-15T12:30:00Z"}
+//	  d:
+//	    d1.test: {size: 0, mtime: "2022-06-15T12:30:00Z"}
+//
+// Empty directories are mapped to an empty mapping, {}.
+func (t TreeFS) YAML(keys ...string) string {
+	var b strings.Builder
+	for _, root := range t.roots {
+		writeYAML(&b, root, 0, keys)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Write the YAML mapping entry of the Node n, and its children, to b,
+// indented as at depth depth.
+func writeYAML(b *strings.Builder, n *Node, depth int, keys []string) {
+	b.WriteString(strings.Repeat("  ", depth) + yamlString(n.Name) + ":")
+
+	if n.IsDir {
+		if len(n.Children) == 0 {
+			b.WriteString(" {}\n")
+			return
+		}
+		b.WriteString("\n")
+		for _, child := range n.Children {
+			writeYAML(b, child, depth+1, keys)
+		}
+		return
+	}
+
+	if fields := yamlMetadata(n, keys); fields != nil {
+		b.WriteString(" {" + strings.Join(fields, ", ") + "}\n")
+		return
+	}
+	b.WriteString(" null\n")
+}
+
+// Return the "key: value" fields of the metadata of the Node n with the keys
+// keys, or nil if there are none.
+func yamlMetadata(n *Node, keys []string) (fields []string) {
+	fi, _ := n.info()
+	for _, key := range keys {
+		var value string
+		switch {
+		case key == "type":
+			value = entryType(n.mode())
+		case key == "size" && fi != nil:
+			value = strconv.FormatInt(fi.Size(), 10)
+		case key == "mode" && fi != nil:
+			value = yamlString(fi.Mode().String())
+		case key == "mtime" && fi != nil:
+			value = yamlString(fi.ModTime().Format(time.RFC3339))
+		default:
+			continue
+		}
+		fields = append(fields, key+": "+value)
+	}
+	return fields
+}
+
+// Words that YAML parsers may resolve to booleans or null when unquoted.
+var yamlReserved = map[string]bool{
+	"true": true, "false": true, "yes": true, "no": true, "on": true,
+	"off": true, "y": true, "n": true, "null": true,
+}
+
+// Return s as a YAML scalar, quoted unless it is unambiguously a plain
+// string.
+func yamlString(s string) string {
+	plain := s != "" && !yamlReserved[strings.ToLower(s)]
+	for i, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+		case i > 0 && (r >= '0' && r <= '9' || r == '.' || r == '-' || r == '/'):
+		default:
+			plain = false
+		}
+	}
+	if plain {
+		return s
+	}
+	return strconv.Quote(s)
+}
@@ -0,0 +1,44 @@
+package treefs
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestYAML(t *testing.T) {
+	mtime := time.Date(2022, time.June, 15, 12, 30, 0, 0, time.UTC)
+	mapfs := fstest.MapFS{
+		"a/a1.test":   {Data: make([]byte, 12), Mode: 0o644, ModTime: mtime},
+		"a/10":        {Mode: 0o600, ModTime: mtime},
+		"a/no":        {Mode: 0o600, ModTime: mtime},
+		`a/b "c"`:     {Mode: 0o600, ModTime: mtime},
+		"a/d/d1.test": {Mode: 0o644, ModTime: mtime},
+		"a/e":         {Mode: fs.ModeDir | 0o755},
+	}
+
+	tfs, err := New(mapfs, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `
+a:
+  "10": null
+  a1.test: null
+  "b \"c\"": null
+  d:
+    d1.test: null
+  e: {}
+  "no": null`[1:]
+	compare(t, tfs.YAML(), expected)
+
+	tfs, err = New(mapfs, "a/d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `
+a/d:
+  d1.test: {type: file, size: 0, mode: "-rw-r--r--", mtime: "2022-06-15T12:30:00Z"}`[1:]
+	compare(t, tfs.YAML("type", "size", "mode", "mtime", "unknown"), expected)
+}